@@ -0,0 +1,49 @@
+package cache
+
+// AdmissionPolicy decides whether a new key is worth the entry it would evict
+// Record is called for every Get, so the policy can learn how often keys are requested
+// Admit is called when the cache is full, returning false drops candidate and keeps victim
+type AdmissionPolicy interface {
+	Record(key CacheKey)
+	Admit(candidate, victim CacheKey) bool
+}
+
+// SetAdmissionPolicy makes Put and TryPut consult policy before a new key evicts an entry, a
+// rejected key is not stored and is counted in Stats.Rejected. The victim is compared without
+// being evicted, so a rejection leaves the eviction policy untouched unless the policy doesn't
+// implement CandidatePolicy
+func (c *Cache) SetAdmissionPolicy(policy AdmissionPolicy) {
+	c.admission = policy
+}
+
+// admitter returns the admission check of candidate for electVictim, nil without an admission
+// policy
+func (c *Cache) admitter(candidate CacheKey) func(victim CacheKey) bool {
+	if c.admission == nil {
+		return nil
+	}
+	return func(victim CacheKey) bool {
+		return c.admission.Admit(candidate, victim)
+	}
+}
+
+// TinyLFU admits a key only when it was requested at least as often as the victim, counted
+// approximately by a count-min sketch. Counts are halved every ten times the capacity requests
+// so that keys that were popular once don't stay ahead forever
+type TinyLFU struct {
+	sketch *countMinSketch
+}
+
+func NewTinyLFU(capacity int) AdmissionPolicy {
+	policy := &TinyLFU{}
+	policy.sketch = newCountMinSketch(capacity, 10*capacity)
+	return policy
+}
+
+func (p *TinyLFU) Record(key CacheKey) {
+	p.sketch.increment(key)
+}
+
+func (p *TinyLFU) Admit(candidate, victim CacheKey) bool {
+	return p.sketch.estimate(candidate) >= p.sketch.estimate(victim)
+}
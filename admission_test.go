@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestCountMinSketch(t *testing.T) {
+	sketch := newCountMinSketch(64, 0)
+	for i := 0; i < 64; i++ {
+		for j := 0; j <= i%8; j++ {
+			sketch.increment(CacheKey(fmt.Sprint(i)))
+		}
+	}
+	exact := 0
+	for i := 0; i < 64; i++ {
+		count := sketch.estimate(CacheKey(fmt.Sprint(i)))
+		if int(count) < i%8+1 {
+			t.Errorf("estimate of %d = %d, but should never be below %d", i, count, i%8+1)
+		}
+		if int(count) == i%8+1 {
+			exact++
+		}
+	}
+	if exact < 48 {
+		t.Errorf("only %d out of 64 estimates are exact", exact)
+	}
+
+	sketch.reset()
+	if count := sketch.estimate("7"); count < 4 || count > 7 {
+		t.Errorf("estimate = %d after halving, but want about 4", count)
+	}
+}
+
+func TestTinyLFUAdmission(t *testing.T) {
+	cache := NewCache(2, LRU)
+	cache.SetAdmissionPolicy(NewTinyLFU(2))
+	for i := 0; i < 3; i++ {
+		cache.Get("1")
+		cache.Get("2")
+	}
+	test(t, cache, [][]interface{}{
+		{"Put", "1", "1"},
+		{"Put", "2", "2"},
+		{"Put", "3", "3"}, // never requested, rejected in favor of 1
+		{"Get", "3", nil},
+		{"Get", "1", "1"},
+		{"Get", "2", "2"},
+	})
+	if stats := cache.Stats(); stats.Rejected != 1 {
+		t.Errorf("rejected = %d, but want 1", stats.Rejected)
+	}
+
+	for i := 0; i < 5; i++ {
+		cache.Get("3")
+	}
+	test(t, cache, [][]interface{}{
+		{"Put", "3", "3"}, // requested more often than 1, 1 is evicted
+		{"Get", "1", nil},
+		{"Get", "2", "2"},
+		{"Get", "3", "3"},
+	})
+	if ok := cache.TryPut("4", "4"); ok {
+		t.Errorf("TryPut should be rejected by the admission policy")
+	}
+}
+
+type rejectAll struct{}
+
+func (rejectAll) Record(CacheKey)                       {}
+func (rejectAll) Admit(candidate, victim CacheKey) bool { return false }
+
+func TestAdmissionKeepsVictimState(t *testing.T) {
+	cache := NewCache(2, LFU)
+	cache.Put("1", "1")
+	for i := 0; i < 5; i++ {
+		cache.Get("1")
+	}
+	cache.Put("2", "2")
+	cache.Get("2")
+	cache.SetAdmissionPolicy(rejectAll{})
+	cache.Put("3", "3") // 2 is the victim, it is kept
+	if count, _ := cache.AccessCount("2"); count != 2 {
+		t.Errorf("access count of key = 2 should stay 2, but got %d", count)
+	}
+	if count, _ := cache.AccessCount("1"); count != 6 {
+		t.Errorf("access count of key = 1 should stay 6, but got %d", count)
+	}
+}
+
+func TestReceiveFromRejected(t *testing.T) {
+	for _, policy := range []PolicyType{LRU, LFU} {
+		src := NewCache(2, LFU)
+		src.Put("1", "1")
+		src.Put("2", "2")
+		src.Put("3", "3")
+		for _, key := range []CacheKey{"1", "2", "3"} {
+			src.Get(key)
+		}
+		var buf bytes.Buffer
+		if err := src.TransferTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+
+		dst := NewCache(1, policy)
+		dst.SetAdmissionPolicy(rejectAll{})
+		if _, err := dst.ReceiveFrom(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if dst.size != 1 || len(dst.meta) != 1 {
+			t.Errorf("policy = %d, the cache should hold the first entry only, but has %d", policy, dst.size)
+		}
+		if evicted := dst.EvictN(2); len(evicted) != 1 {
+			t.Errorf("policy = %d, only the resident entry should be evicted, but got %v", policy, evicted)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package cache
+
+import "sync/atomic"
+
+type policyEventKind int
+
+const (
+	addEvent policyEventKind = iota
+	removeEvent
+	accessEvent
+	victimEvent
+)
+
+type policyEvent struct {
+	kind   policyEventKind
+	key    CacheKey
+	victim chan CacheKey
+}
+
+// AsyncPolicy decouples policy maintenance from the request path: Add, Remove and Access are
+// published onto a channel consumed by a goroutine that owns the wrapped policy. Access never
+// blocks, when the channel is full the access is dropped and counted in Dropped. Victim is
+// queued behind the pending events so it observes all of them
+type AsyncPolicy struct {
+	policy  CachePolicy
+	events  chan policyEvent
+	dropped int64
+	done    chan struct{}
+}
+
+func NewAsyncPolicy(policy CachePolicy, buffer int) *AsyncPolicy {
+	p := &AsyncPolicy{}
+	p.policy = policy
+	p.events = make(chan policyEvent, buffer)
+	p.done = make(chan struct{})
+	go p.run()
+	return p
+}
+
+func (p *AsyncPolicy) run() {
+	defer close(p.done)
+	for event := range p.events {
+		switch event.kind {
+		case addEvent:
+			p.policy.Add(event.key)
+		case removeEvent:
+			p.policy.Remove(event.key)
+		case accessEvent:
+			p.policy.Access(event.key)
+		case victimEvent:
+			event.victim <- p.policy.Victim()
+		}
+	}
+}
+
+func (p *AsyncPolicy) Victim() CacheKey {
+	victim := make(chan CacheKey)
+	p.events <- policyEvent{kind: victimEvent, victim: victim}
+	return <-victim
+}
+
+func (p *AsyncPolicy) Add(key CacheKey) {
+	p.events <- policyEvent{kind: addEvent, key: key}
+}
+
+func (p *AsyncPolicy) Remove(key CacheKey) {
+	p.events <- policyEvent{kind: removeEvent, key: key}
+}
+
+func (p *AsyncPolicy) Access(key CacheKey) {
+	select {
+	case p.events <- policyEvent{kind: accessEvent, key: key}:
+	default:
+		atomic.AddInt64(&p.dropped, 1)
+	}
+}
+
+// Dropped returns the number of accesses dropped because the channel was full
+func (p *AsyncPolicy) Dropped() int64 {
+	return atomic.LoadInt64(&p.dropped)
+}
+
+// QueueDepth returns the number of events waiting to be applied
+func (p *AsyncPolicy) QueueDepth() int {
+	return len(p.events)
+}
+
+// Close stops the policy goroutine once the pending events are applied
+func (p *AsyncPolicy) Close() {
+	close(p.events)
+	<-p.done
+}
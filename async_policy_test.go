@@ -0,0 +1,26 @@
+package cache
+
+import "testing"
+
+func TestAsyncPolicy(t *testing.T) {
+	policy := NewAsyncPolicy(NewLRUPolicy(), 16)
+	defer policy.Close()
+
+	testCase := [][]interface{}{
+		{"Put", "1", "1"},
+		{"Put", "2", "2"},
+		{"Put", "3", "3"},
+		{"Get", "1", "1"},
+		{"Put", "4", "4"}, // 2 is evicted
+		{"Get", "2", nil},
+		{"Get", "1", "1"},
+		{"Get", "3", "3"},
+		{"Get", "4", "4"},
+	}
+
+	cache := NewCacheWithPolicy(3, policy)
+	test(t, cache, testCase)
+	if policy.Dropped() != 0 {
+		t.Errorf("no access should be dropped, but got %d", policy.Dropped())
+	}
+}
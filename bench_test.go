@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+var benchmarkPolicies = []struct {
+	name   string
+	policy PolicyType
+}{
+	{"FIFO", FIFO},
+	{"LRU", LRU},
+	{"LFU", LFU},
+	{"CLOCK", CLOCK},
+	{"SLRU", SLRU},
+	{"LIRS", LIRS},
+	{"GDSF", GDSF},
+	{"MRU", MRU},
+	{"RANDOM", RANDOM},
+}
+
+// BenchmarkPolicyContention runs a read-heavy zipf workload against a single lock cache from an
+// increasing number of goroutines, reporting throughput and p99 latency. Compare runs with
+// benchstat to spot lock contention regressions
+func BenchmarkPolicyContention(b *testing.B) {
+	const capacity = 1000
+
+	for _, p := range benchmarkPolicies {
+		for _, goroutines := range []int{1, 4, 16, 64} {
+			b.Run(fmt.Sprintf("%s/goroutines=%d", p.name, goroutines), func(b *testing.B) {
+				cache, _ := NewShardedCache(1, capacity, p.policy, nil)
+				latencies := make([][]time.Duration, goroutines)
+
+				var wg sync.WaitGroup
+				b.ResetTimer()
+				for g := 0; g < goroutines; g++ {
+					wg.Add(1)
+					go func(g int) {
+						defer wg.Done()
+						zipf := rand.NewZipf(rand.New(rand.NewSource(int64(g))), 1.1, 1, 4*capacity)
+						ops := b.N / goroutines
+						if g < b.N%goroutines {
+							ops++
+						}
+						latencies[g] = make([]time.Duration, 0, ops)
+						for i := 0; i < ops; i++ {
+							key := CacheKey(fmt.Sprint(zipf.Uint64()))
+							start := time.Now()
+							if _, err := cache.Get(key); err != nil {
+								cache.Put(key, "")
+							}
+							latencies[g] = append(latencies[g], time.Since(start))
+						}
+					}(g)
+				}
+				wg.Wait()
+				b.StopTimer()
+
+				all := []time.Duration{}
+				for _, l := range latencies {
+					all = append(all, l...)
+				}
+				sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+				if len(all) > 0 {
+					b.ReportMetric(float64(all[len(all)*99/100]), "p99-ns")
+				}
+			})
+		}
+	}
+}
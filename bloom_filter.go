@@ -0,0 +1,91 @@
+package cache
+
+import "hash/fnv"
+
+const (
+	bloomBitsPerKey = 10
+	bloomHashes     = 7 // about 1% false positives at 10 bits per key
+)
+
+// bloomFilter is a fixed size bloom filter over cache keys
+type bloomFilter struct {
+	bits   []uint64
+	hashes int
+}
+
+func newBloomFilter(keys int) *bloomFilter {
+	if keys < 1 {
+		keys = 1
+	}
+	filter := &bloomFilter{}
+	filter.bits = make([]uint64, (keys*bloomBitsPerKey+63)/64)
+	filter.hashes = bloomHashes
+	return filter
+}
+
+func (f *bloomFilter) add(key CacheKey) {
+	h1, h2 := bloomHash(key)
+	size := uint64(len(f.bits) * 64)
+	for i := 0; i < f.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) % size
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+func (f *bloomFilter) contains(key CacheKey) bool {
+	h1, h2 := bloomHash(key)
+	size := uint64(len(f.bits) * 64)
+	for i := 0; i < f.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) % size
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomHash derives the two hashes used for double hashing
+func bloomHash(key CacheKey) (uint64, uint64) {
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	sum := hash.Sum64()
+	return sum, sum>>32 | 1
+}
+
+// mixedHash is fnv with the splitmix64 finalizer, fnv alone leaves the high bits poorly mixed
+// for short keys
+func mixedHash(key CacheKey) uint64 {
+	hash, _ := bloomHash(key)
+	hash ^= hash >> 30
+	hash *= 0xbf58476d1ce4e5b9
+	hash ^= hash >> 27
+	hash *= 0x94d049bb133111eb
+	hash ^= hash >> 31
+	return hash
+}
+
+// EnableBloomFilter keeps a bloom filter of resident keys so that Get can reject most misses
+// without touching the data map. Bloom filters can't forget keys, so the filter is rebuilt from
+// the resident keys once as many keys as the cache capacity have been removed, and on Resize
+func (c *Cache) EnableBloomFilter() {
+	c.rebuildFilter()
+}
+
+func (c *Cache) rebuildFilter() {
+	c.filter = newBloomFilter(c.maxSize)
+	for key := range c.data {
+		c.filter.add(key)
+	}
+	c.filterRemovals = 0
+	c.logger.Debug("bloom filter rebuilt", "keys", len(c.data))
+}
+
+func (c *Cache) filterRemoved() {
+	if c.filter == nil {
+		return
+	}
+	c.filterRemovals++
+	if c.filterRemovals >= c.maxSize {
+		c.rebuildFilter()
+	}
+}
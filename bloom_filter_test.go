@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBloomFilter(t *testing.T) {
+	filter := newBloomFilter(1000)
+	for i := 0; i < 1000; i++ {
+		filter.add(CacheKey(fmt.Sprint(i)))
+	}
+	for i := 0; i < 1000; i++ {
+		if !filter.contains(CacheKey(fmt.Sprint(i))) {
+			t.Fatalf("key = %d should be in the filter", i)
+		}
+	}
+
+	falsePositives := 0
+	for i := 1000; i < 11000; i++ {
+		if filter.contains(CacheKey(fmt.Sprint(i))) {
+			falsePositives++
+		}
+	}
+	if falsePositives > 300 {
+		t.Errorf("false positives should be around 1%%, but got %d out of 10000", falsePositives)
+	}
+}
+
+func TestCacheBloomFilter(t *testing.T) {
+	cache := NewCache(2, LRU)
+	cache.Put("1", "1")
+	cache.EnableBloomFilter()
+	test(t, cache, [][]interface{}{
+		{"Put", "2", "2"},
+		{"Get", "1", "1"},
+		{"Get", "2", "2"},
+		{"Put", "3", "3"}, // 1 is evicted
+		{"Get", "1", nil},
+		{"Put", "4", "4"}, // 2 is evicted, the filter is rebuilt
+		{"Get", "2", nil},
+		{"Get", "3", "3"},
+		{"Get", "4", "4"},
+	})
+	if cache.filterRemovals != 0 {
+		t.Errorf("filter removals should be reset by the rebuild, but got %d", cache.filterRemovals)
+	}
+	// a filter with two keys in 64 bits has false positives, compare with the resident keys alone
+	resident := newBloomFilter(2)
+	resident.add("3")
+	resident.add("4")
+	if fmt.Sprint(cache.filter.bits) != fmt.Sprint(resident.bits) {
+		t.Errorf("evicted keys should be dropped by the rebuild")
+	}
+}
+
+func TestBloomFilterResize(t *testing.T) {
+	cache := NewCache(2, LRU)
+	cache.EnableBloomFilter()
+	cache.Resize(1000)
+	for i := 0; i < 1000; i++ {
+		cache.Put(CacheKey(fmt.Sprint(i)), "")
+	}
+	if bits := len(cache.filter.bits) * 64; bits < 1000*bloomBitsPerKey {
+		t.Errorf("filter should grow with the capacity, but has %d bits", bits)
+	}
+}
@@ -4,17 +4,101 @@ import (
 	"container/list"
 	"container/ring"
 	"errors"
+	"math"
+	"sort"
+	"sync/atomic"
+	"time"
 )
 
 type CacheKey string
 
 type CacheData map[CacheKey]string
 
+// Entry is a key with its value
+type Entry struct {
+	Key   CacheKey
+	Value string
+}
+
 type Cache struct {
-	maxSize int
-	size    int
-	policy  CachePolicy
-	data    CacheData
+	maxSize  int
+	size     int
+	peakSize int // largest size since the last Compact
+	policy   CachePolicy
+	data     CacheData
+
+	tombstoneGrace time.Duration
+	tombstones     map[CacheKey]time.Time
+	lateWrites     int
+	now            func() time.Time
+
+	filter         *bloomFilter
+	filterRemovals int
+	index          *orderedIndex
+	values         *valuePool
+	missCosts      *missCostTable
+	trackWriters   bool
+	distinct       atomic.Value // *distinctKeys
+	distinctWindow time.Duration
+	admission      AdmissionPolicy
+
+	meta      map[CacheKey]*entryMeta
+	slots     []CacheKey                 // resident keys, unordered, for iteration and sampling
+	iterators map[*SnapshotIterator]bool // open IterateSnapshot iterators
+	epoch     uint64
+	ops       uint64 // Put and Get calls
+
+	warmupUntil    time.Time
+	warmupHitRatio float64
+	doorkeeper     *countMinSketch // misses per key during warm-up, nil once warm
+
+	stickyFor    time.Duration
+	stickyOps    uint64
+	victimFilter VictimFilter
+
+	keyTransformer KeyTransformer
+
+	view      *View
+	published *publishedView
+
+	stats   counters
+	evicted *evictionHistory
+	window  hitWindow
+	sources map[string]*SourceStats
+
+	parents []Parent
+
+	logger Logger
+
+	lastSnapshot time.Time
+
+	onEvict         EvictionCallback
+	panicMode       PanicMode
+	dispatcher      *callbackDispatcher
+	callbackMode    CallbackMode
+	callbackWorkers int
+	maxInFlight     int
+	shedding        SheddingPolicy
+	name            string
+
+	fullSince      time.Time // zero while the cache is not full
+	timeAtFull     time.Duration
+	fullAlarm      time.Duration
+	onFull         func(fullFor time.Duration)
+	fullAlarmFired bool
+}
+
+// entryMeta holds the bookkeeping kept alongside each resident entry
+type entryMeta struct {
+	epoch      uint64
+	insertedAt time.Time
+	writtenAt  time.Time
+	insertedOp uint64
+	accessed   bool // hit at least once since insert
+	source     string
+	slot       int // index in Cache.slots
+	writer     string
+	cost       float64 // see PutWithCost
 }
 
 type PolicyType int
@@ -24,6 +108,11 @@ const (
 	LRU
 	LFU
 	CLOCK
+	SLRU
+	LIRS
+	GDSF
+	MRU
+	RANDOM
 )
 
 // Victim runs the policy algorithm and elects a CacheKey , called victim, for removal;
@@ -37,6 +126,34 @@ type CachePolicy interface {
 	Access(CacheKey)
 }
 
+// CandidatePolicy is implemented by policies that can offer eviction candidates without evicting
+// them, so that the cache can skip protected keys without disturbing the policy state
+// Candidates calls fn with resident keys, best victim first, until fn returns false
+// Evict takes a key offered by Candidates out of the policy, as Victim would have
+type CandidatePolicy interface {
+	CachePolicy
+	Candidates(fn func(CacheKey) bool)
+	Evict(CacheKey)
+}
+
+// candidatePolicy returns policy as a CandidatePolicy, middlewares only qualify when the policy
+// they wrap does
+func candidatePolicy(policy CachePolicy) (CandidatePolicy, bool) {
+	if wrapper, ok := policy.(interface{ supportsCandidates() bool }); ok && !wrapper.supportsCandidates() {
+		return nil, false
+	}
+	candidates, ok := policy.(CandidatePolicy)
+	return candidates, ok
+}
+
+// FrequencyPolicy is implemented by policies that track access frequencies
+// Frequency returns the frequency of a resident key
+// Histogram returns the number of resident keys for each frequency
+type FrequencyPolicy interface {
+	Frequency(CacheKey) (Frequency, bool)
+	Histogram() map[Frequency]int
+}
+
 func GetCachePolicy(policy PolicyType) CachePolicy {
 	switch policy {
 	case FIFO:
@@ -47,36 +164,406 @@ func GetCachePolicy(policy PolicyType) CachePolicy {
 		return NewLFUPolicy()
 	case CLOCK:
 		return NewCLOCKPolicy()
+	case SLRU:
+		return NewSLRUPolicy()
+	case LIRS:
+		return NewLIRSPolicy()
+	case GDSF:
+		return NewGDSFPolicy()
+	case MRU:
+		return NewMRUPolicy()
+	case RANDOM:
+		return NewRandomPolicy()
 	default:
 		return NewFIFOPolicy()
 	}
 }
 
 func (c *Cache) Put(key CacheKey, value string) {
-	if c.size == c.maxSize {
-		victimKey := c.policy.Victim()
-		delete(c.data, victimKey)
-		c.size -= 1
+	c.put(c.canonical(key), value, "", 0)
+}
+
+// PutWithSource stores key with a label of the population it comes from, e.g. "loader" or
+// "warmup", so that SourceStats shows which population earns its cache space
+func (c *Cache) PutWithSource(key CacheKey, value string, source string) {
+	c.put(c.canonical(key), value, source, 0)
+}
+
+// PutWithFlags is Put with per-call flags, e.g. NoStore for bulk backfills that should only
+// refresh resident entries
+func (c *Cache) PutWithFlags(key CacheKey, value string, flags CallFlags) {
+	c.put(c.canonical(key), value, "", flags)
+}
+
+// put reports whether key is stored after the call
+func (c *Cache) put(key CacheKey, value string, source string, flags CallFlags) bool {
+	c.ops++
+	c.purgeTombstones()
+	if _, ok := c.data[key]; ok {
+		c.touch(key)
+		if _, ok := c.tombstones[key]; ok {
+			// a write arriving after the delete, the tombstone is lifted
+			delete(c.tombstones, key)
+			c.lateWrites++
+			c.logger.Warn("late write on deleted key", "key", key)
+		}
+		if flags&NoRecord == 0 {
+			c.policy.Access(key)
+		}
+		if c.values != nil {
+			c.values.release(c.data[key])
+			value = c.values.intern(value)
+		}
+		c.data[key] = value
+		c.publishWrite(key, value)
+		c.meta[key].epoch = c.epoch
+		c.meta[key].source = source
+		c.meta[key].writtenAt = c.now()
+		if c.trackWriters {
+			c.meta[key].writer = writer()
+		}
+		c.setCost(key)
+		c.updateFullness() // overwrites are writes too, the alarm may be due
+		return true
+	}
+
+	if flags&NoStore != 0 || c.maxSize <= 0 || !c.admitted(key) {
+		return false
+	}
+	if c.size >= c.maxSize {
+		victimKey, ok := c.electVictim(true, c.admitter(key))
+		if !ok {
+			atomic.AddInt64(&c.stats.rejected, 1)
+			return false
+		}
+		c.evictKey(victimKey)
 	}
 	c.policy.Add(key)
+	if c.values != nil {
+		value = c.values.intern(value)
+	}
 	c.data[key] = value
+	c.publishWrite(key, value)
+	now := c.now()
+	c.meta[key] = &entryMeta{epoch: c.epoch, insertedAt: now, writtenAt: now, insertedOp: c.ops, source: source, cost: 1}
+	c.addSlot(key)
+	if c.trackWriters {
+		c.meta[key].writer = writer()
+	}
+	c.setCost(key)
 	c.size += 1
+	if c.size > c.peakSize {
+		c.peakSize = c.size
+	}
+	if c.filter != nil {
+		c.filter.add(key)
+	}
+	if c.index != nil {
+		c.index.add(key)
+	}
+	c.updateFullness()
+	return true
 }
 
 func (c *Cache) Get(key CacheKey) (*string, error) {
-	if value, ok := c.data[key]; ok {
-		c.policy.Access(key)
-		return &value, nil
+	return c.get(c.canonical(key), 0)
+}
+
+// GetWithFlags is Get with per-call flags, e.g. NoRecord for diagnostic reads that must not
+// perturb eviction state or statistics
+func (c *Cache) GetWithFlags(key CacheKey, flags CallFlags) (*string, error) {
+	return c.get(c.canonical(key), flags)
+}
+
+func (c *Cache) get(key CacheKey, flags CallFlags) (*string, error) {
+	record := flags&NoRecord == 0
+	if record {
+		c.ops++
+		c.recordDistinct(key)
+		if c.admission != nil {
+			c.admission.Record(key)
+		}
+	}
+	if c.filter == nil || c.filter.contains(key) {
+		if value, ok := c.data[key]; ok && !c.tombstoned(key) && !c.outdated(key) {
+			if record {
+				c.policy.Access(key)
+				c.hit(key)
+			}
+			return &value, nil
+		}
 	}
 
+	if record {
+		c.miss(key)
+	}
+	for _, parent := range c.parents {
+		if value, err := parent.Get(key); err == nil {
+			if record {
+				atomic.AddInt64(&c.stats.parentHits, 1)
+			}
+			c.put(key, *value, "", flags)
+			return value, nil
+		}
+	}
 	return nil, errors.New("key not found")
 }
 
+// CallFlags change how a single call interacts with the cache
+type CallFlags int
+
+const (
+	// NoRecord leaves the policy and the statistics untouched
+	NoRecord CallFlags = 1 << iota
+	// NoStore never stores a new entry, resident entries can still be updated
+	NoStore
+)
+
+// Parent is a cache queried when a key is missing locally, *Cache and *ShardedCache implement it
+type Parent interface {
+	Get(CacheKey) (*string, error)
+}
+
+// AddParent appends a parent to the list queried in order on a miss, the first parent hit is
+// stored locally. Parents must not form a cycle
+func (c *Cache) AddParent(parent Parent) {
+	c.parents = append(c.parents, parent)
+}
+
+// GetNotOlderThan is Get for callers with stricter freshness needs, an entry written more than
+// maxAge ago is reported as a miss. The entry stays in the cache for other callers
+func (c *Cache) GetNotOlderThan(key CacheKey, maxAge time.Duration) (*string, error) {
+	key = c.canonical(key)
+	if meta, ok := c.meta[key]; ok && c.now().Sub(meta.writtenAt) > maxAge {
+		c.ops++
+		c.miss(key)
+		return nil, errors.New("key too old")
+	}
+	return c.Get(key)
+}
+
+// Peek returns the value of key without updating the policy, tombstoned reports whether
+// the key has been deleted and is waiting for the end of its grace period. Like Get it removes
+// a tombstone whose grace period is over
+func (c *Cache) Peek(key CacheKey) (value *string, tombstoned bool, err error) {
+	key = c.canonical(key)
+	if value, ok := c.data[key]; ok && !c.outdated(key) {
+		return &value, c.tombstoned(key), nil
+	}
+
+	return nil, false, errors.New("key not found")
+}
+
+// Delete removes key from the cache. When a tombstone grace period is set the entry is only
+// marked as deleted, hidden from Get but visible to Peek, and physically removed once the grace
+// period is over
+func (c *Cache) Delete(key CacheKey) {
+	key = c.canonical(key)
+	if _, ok := c.data[key]; !ok {
+		return
+	}
+	if c.tombstoneGrace <= 0 {
+		c.remove(key)
+		return
+	}
+	if _, ok := c.tombstones[key]; !ok {
+		c.touch(key)
+		c.tombstones[key] = c.now().Add(c.tombstoneGrace)
+		c.publishRemoval(key)
+	}
+}
+
+// DeleteFunc deletes every entry for which match returns true in a single pass, for
+// invalidations prefixes can't express, e.g. every entry written before an epoch. It returns the
+// number of entries deleted
+func (c *Cache) DeleteFunc(match func(key CacheKey, info EntryInfo) bool) int {
+	deleted := 0
+	for key := range c.data {
+		if _, ok := c.tombstones[key]; !ok && match(key, c.info(key)) {
+			c.Delete(key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// SetTombstoneGrace sets how long deleted entries are kept as tombstones, zero disables tombstones
+func (c *Cache) SetTombstoneGrace(grace time.Duration) {
+	c.tombstoneGrace = grace
+}
+
+// LateWrites returns the number of Put calls that hit a tombstoned key
+func (c *Cache) LateWrites() int {
+	return c.lateWrites
+}
+
+func (c *Cache) tombstoned(key CacheKey) bool {
+	expiry, ok := c.tombstones[key]
+	if !ok {
+		return false
+	}
+	if !c.now().Before(expiry) {
+		c.remove(key)
+	}
+	return true
+}
+
+func (c *Cache) purgeTombstones() {
+	if len(c.tombstones) == 0 {
+		return
+	}
+	now := c.now()
+	for key, expiry := range c.tombstones {
+		if !now.Before(expiry) {
+			c.remove(key)
+		}
+	}
+}
+
+// evict removes the victim elected by the policy, it returns false when there is no evictable key
+func (c *Cache) evict() (Entry, bool) {
+	victimKey, ok := c.electVictim(true, nil)
+	if !ok {
+		return Entry{}, false
+	}
+	return c.evictKey(victimKey), true
+}
+
+// evictKey drops a victim already removed from the policy
+func (c *Cache) evictKey(victimKey CacheKey) Entry {
+	value := c.data[victimKey]
+	if !c.meta[victimKey].accessed {
+		atomic.AddInt64(&c.stats.oneHitWonders, 1)
+	}
+	c.sourceStats(c.meta[victimKey].source).Evictions++
+	c.removeSlot(victimKey)
+	if c.values != nil {
+		c.values.release(value)
+	}
+	delete(c.data, victimKey)
+	delete(c.tombstones, victimKey)
+	delete(c.meta, victimKey)
+	c.size -= 1
+	c.publishRemoval(victimKey)
+	c.filterRemoved()
+	if c.index != nil {
+		c.index.remove(victimKey)
+	}
+	atomic.AddInt64(&c.stats.evictions, 1)
+	c.recordEviction(victimKey)
+	if onEvict := c.onEvict; onEvict != nil {
+		// the callback may run later on a worker, it must not see a later SetOnEvict
+		c.dispatch(func() { onEvict(victimKey, value) })
+	}
+	return Entry{victimKey, value}
+}
+
+// EvictN performs up to n policy driven evictions and returns the evicted entries
+func (c *Cache) EvictN(n int) []Entry {
+	if n <= 0 {
+		return []Entry{}
+	}
+	evicted := make([]Entry, 0, n)
+	for i := 0; i < n && c.size > 0; i++ {
+		entry, ok := c.evict()
+		if !ok {
+			break
+		}
+		evicted = append(evicted, entry)
+	}
+	c.updateFullness()
+	return evicted
+}
+
+// AccessCount returns the access frequency of key as tracked by the policy, the insert counts
+// as the first access
+func (c *Cache) AccessCount(key CacheKey) (int, error) {
+	key = c.canonical(key)
+	policy, ok := basePolicy(c.policy).(FrequencyPolicy)
+	if !ok {
+		return 0, errors.New("policy does not track frequencies")
+	}
+	if frequency, ok := policy.Frequency(key); ok {
+		return int(frequency), nil
+	}
+
+	return 0, errors.New("key not found")
+}
+
+// FrequencyHistogram returns the number of resident keys for each access frequency
+func (c *Cache) FrequencyHistogram() (map[Frequency]int, error) {
+	policy, ok := basePolicy(c.policy).(FrequencyPolicy)
+	if !ok {
+		return nil, errors.New("policy does not track frequencies")
+	}
+
+	return policy.Histogram(), nil
+}
+
+// Resize changes the capacity of the cache, evicting entries when it shrinks below the current
+// size. Keys that can't be evicted, such as pinned or vetoed keys, stay even when that leaves the
+// cache over capacity
+func (c *Cache) Resize(maxSize int) {
+	c.maxSize = maxSize
+	for c.size > c.maxSize {
+		if _, ok := c.evict(); !ok {
+			break
+		}
+	}
+	if c.filter != nil {
+		c.rebuildFilter() // the filter is sized for the capacity
+	}
+	c.updateFullness()
+}
+
+// NewEpoch logically invalidates every entry written before it in O(1), outdated entries are
+// removed lazily when they are next read
+func (c *Cache) NewEpoch() uint64 {
+	c.epoch++
+	c.unpublish()
+	return c.epoch
+}
+
+func (c *Cache) outdated(key CacheKey) bool {
+	if c.meta[key].epoch < c.epoch {
+		c.remove(key)
+		return true
+	}
+	return false
+}
+
+func (c *Cache) remove(key CacheKey) {
+	c.policy.Remove(key)
+	c.removeSlot(key)
+	if c.values != nil {
+		c.values.release(c.data[key])
+	}
+	delete(c.data, key)
+	delete(c.tombstones, key)
+	delete(c.meta, key)
+	c.size -= 1
+	c.publishRemoval(key)
+	c.filterRemoved()
+	if c.index != nil {
+		c.index.remove(key)
+	}
+	c.updateFullness()
+}
+
 func NewCache(maxSize int, policy PolicyType) *Cache {
+	return NewCacheWithPolicy(maxSize, GetCachePolicy(policy))
+}
+
+// NewCacheWithPolicy creates a cache using a caller supplied policy
+func NewCacheWithPolicy(maxSize int, policy CachePolicy) *Cache {
 	cache := &Cache{}
 	cache.maxSize = maxSize
-	cache.policy = GetCachePolicy(policy)
+	cache.policy = policy
 	cache.data = make(CacheData, maxSize)
+	cache.tombstones = make(map[CacheKey]time.Time)
+	cache.now = time.Now
+	cache.meta = make(map[CacheKey]*entryMeta, maxSize)
+	cache.logger = nopLogger{}
 	return cache
 }
 
@@ -157,6 +644,25 @@ func (p *LRUPolicy) Access(key CacheKey) {
 	p.Add(key)
 }
 
+// MRU evicts the most recently used key, which beats LRU on cyclic scans larger than the cache
+type MRUPolicy struct {
+	LRUPolicy
+}
+
+func NewMRUPolicy() CachePolicy {
+	policy := &MRUPolicy{}
+	policy.list = list.New()
+	policy.keyNode = make(map[CacheKey]*list.Element)
+	return policy
+}
+
+func (p *MRUPolicy) Victim() CacheKey {
+	element := p.list.Front()
+	p.list.Remove(element)
+	delete(p.keyNode, element.Value.(CacheKey))
+	return element.Value.(CacheKey)
+}
+
 // CLOCK
 type ClockPolicy struct {
 	list      *CircularList
@@ -188,10 +694,14 @@ func (p *ClockPolicy) Victim() CacheKey {
 			p.clockHand = currentNode.Next()
 		} else {
 			victimKey = nodeItem.key
+			next := p.clockHand.Next()
 			p.list.Move(p.clockHand.Prev())
 			p.clockHand = nil
 			p.list.Remove(&currentNode)
 			delete(p.keyNode, victimKey)
+			if p.list.Len() > 0 {
+				p.clockHand = next // the hand moves on, the next Victim doesn't need an Add first
+			}
 			return victimKey
 		}
 	}
@@ -216,6 +726,9 @@ func (p *ClockPolicy) Remove(key CacheKey) {
 	}
 	p.list.Remove(node)
 	delete(p.keyNode, key)
+	if p.list.Len() == 0 {
+		p.clockHand = nil
+	}
 }
 
 func (p *ClockPolicy) Access(key CacheKey) {
@@ -233,28 +746,96 @@ type Frequency int
 type LFUItem struct {
 	frequency Frequency
 	key       CacheKey
+	rest      float64 // what rounding the scaled frequency took away, kept for the next scaling
 }
 
 type LFUPolicy struct {
 	freqList     map[Frequency]*list.List
 	keyNode      map[CacheKey]*list.Element
 	minFrequency Frequency
+
+	maxFrequency Frequency // zero means unbounded
+	halveEvery   int       // zero disables periodic halving
+	accesses     int       // accesses since the last halving
+
+	logBuckets bool
+
+	decayEvery  time.Duration // zero disables time based decay
+	decayFactor float64
+	lastDecay   time.Time
+	now         func() time.Time
+}
+
+// LFUOption configures an LFUPolicy
+type LFUOption func(*LFUPolicy)
+
+// WithMaxFrequency caps frequencies at max, so long lived keys can't accumulate counts that newer
+// popular keys never overtake
+func WithMaxFrequency(max Frequency) LFUOption {
+	return func(p *LFUPolicy) {
+		p.maxFrequency = max
+	}
+}
+
+// WithHalving halves every frequency after each run of accesses, so that old popularity fades
+func WithHalving(accesses int) LFUOption {
+	return func(p *LFUPolicy) {
+		p.halveEvery = accesses
+	}
+}
+
+// WithLogBuckets groups keys in frequency lists by powers of two, 1, 2-3, 4-7 and so on, instead
+// of one list per distinct frequency. This bounds the number of lists to the log of the highest
+// frequency; keys keep their exact frequency but ties within a list are broken by recency
+func WithLogBuckets() LFUOption {
+	return func(p *LFUPolicy) {
+		p.logBuckets = true
+	}
 }
 
-func NewLFUPolicy() CachePolicy {
+// WithHalfLife decays frequencies exponentially with wall-clock time, halving them every halfLife,
+// so the policy tracks current popularity rather than lifetime popularity
+func WithHalfLife(halfLife time.Duration) LFUOption {
+	return func(p *LFUPolicy) {
+		p.decayEvery = halfLife
+		p.decayFactor = 0.5
+	}
+}
+
+// WithDecay multiplies every frequency by factor, between 0 and 1 exclusive, once per interval
+// of wall-clock time. WithHalfLife is WithDecay with a factor of one half. The option is ignored
+// when factor is out of range or interval isn't positive
+func WithDecay(interval time.Duration, factor float64) LFUOption {
+	return func(p *LFUPolicy) {
+		if interval <= 0 || !(factor > 0 && factor < 1) {
+			return
+		}
+		p.decayEvery = interval
+		p.decayFactor = factor
+	}
+}
+
+func NewLFUPolicy(opts ...LFUOption) CachePolicy {
 	policy := &LFUPolicy{}
 	policy.keyNode = make(map[CacheKey]*list.Element)
 	policy.freqList = make(map[Frequency]*list.List)
 	policy.minFrequency = 1
+	policy.now = time.Now
+	policy.lastDecay = policy.now()
+	for _, opt := range opts {
+		opt(policy)
+	}
 	return policy
 }
 
 func (p *LFUPolicy) Victim() CacheKey {
-	fList := p.freqList[p.minFrequency]
-	element := fList.Back()
-	fList.Remove(element)
-	delete(p.keyNode, element.Value.(LFUItem).key)
-	return element.Value.(LFUItem).key
+	p.decay()
+	if _, ok := p.freqList[p.minFrequency]; !ok {
+		p.resetMinFrequency()
+	}
+	key := p.freqList[p.minFrequency].Back().Value.(LFUItem).key
+	p.remove(key)
+	return key
 }
 
 func (p *LFUPolicy) Add(key CacheKey) {
@@ -263,41 +844,162 @@ func (p *LFUPolicy) Add(key CacheKey) {
 		p.freqList[1] = list.New()
 	}
 
-	node := p.freqList[1].PushFront(LFUItem{1, key})
+	node := p.freqList[1].PushFront(LFUItem{1, key, 0})
 	p.keyNode[key] = node
 	p.minFrequency = 1
 }
 
 func (p *LFUPolicy) Remove(key CacheKey) {
+	if _, ok := p.keyNode[key]; !ok {
+		return
+	}
 	p.remove(key)
 }
 
 func (p *LFUPolicy) Access(key CacheKey) {
+	p.decay()
 	node := p.remove(key)
 
-	frequency := node.Value.(LFUItem).frequency
-	_, ok := p.freqList[frequency+1]
+	item := node.Value.(LFUItem)
+	frequency, rest := item.frequency+1, item.rest
+	if p.maxFrequency > 0 && frequency > p.maxFrequency {
+		frequency, rest = p.maxFrequency, 0
+	}
+	bucket := p.bucket(frequency)
+	_, ok := p.freqList[bucket]
 	if !ok {
-		p.freqList[frequency+1] = list.New()
+		p.freqList[bucket] = list.New()
 	}
 
-	node = p.freqList[frequency+1].PushFront(LFUItem{frequency + 1, key})
+	node = p.freqList[bucket].PushFront(LFUItem{frequency, key, rest})
 	p.keyNode[key] = node
+	if bucket < p.minFrequency {
+		p.minFrequency = bucket
+	}
+
+	p.accesses++
+	if p.halveEvery > 0 && p.accesses >= p.halveEvery {
+		p.scale(0.5, false)
+	}
+}
+
+// scale multiplies every frequency by factor and truncates it. With carry it rounds instead and
+// keeps the rounding error for the next scaling, so that a gentle decay, a factor close to 1,
+// neither drops low frequencies at once nor leaves them in place forever. Keys merged into the
+// same frequency keep their relative order with the keys coming from lower frequencies closer to
+// eviction
+func (p *LFUPolicy) scale(factor float64, carry bool) {
+	frequencies := make([]Frequency, 0, len(p.freqList))
+	for frequency := range p.freqList {
+		frequencies = append(frequencies, frequency)
+	}
+	sort.Slice(frequencies, func(i, j int) bool { return frequencies[i] < frequencies[j] })
+
+	freqList := make(map[Frequency]*list.List)
+	for _, frequency := range frequencies {
+		for element := p.freqList[frequency].Back(); element != nil; element = element.Prev() {
+			item := element.Value.(LFUItem)
+			exact := (float64(item.frequency) + item.rest) * factor
+			scaled, rest := Frequency(exact), 0.0
+			if carry {
+				scaled = Frequency(math.Round(exact))
+				rest = exact - float64(scaled)
+			}
+			if scaled < 1 {
+				scaled, rest = 1, 0
+			}
+			bucket := p.bucket(scaled)
+			if _, ok := freqList[bucket]; !ok {
+				freqList[bucket] = list.New()
+			}
+			p.keyNode[item.key] = freqList[bucket].PushFront(LFUItem{scaled, item.key, rest})
+		}
+	}
+	p.freqList = freqList
+	p.accesses = 0
+	p.resetMinFrequency()
+}
+
+// decay scales the frequencies once for every decay interval elapsed since the last decay
+func (p *LFUPolicy) decay() {
+	if p.decayEvery <= 0 {
+		return
+	}
+	decays := int(p.now().Sub(p.lastDecay) / p.decayEvery)
+	p.lastDecay = p.lastDecay.Add(time.Duration(decays) * p.decayEvery)
+	for i := 0; i < decays && len(p.freqList) > 0; i++ {
+		if _, ok := p.freqList[1]; ok && len(p.freqList) == 1 {
+			break // every key is already at the lowest frequency
+		}
+		p.scale(p.decayFactor, true)
+	}
+}
+
+// resetMinFrequency finds the lowest frequency in use
+func (p *LFUPolicy) resetMinFrequency() {
+	p.minFrequency = 1
+	first := true
+	for frequency := range p.freqList {
+		if first || frequency < p.minFrequency {
+			p.minFrequency = frequency
+			first = false
+		}
+	}
 }
 
 func (p *LFUPolicy) remove(key CacheKey) *list.Element {
 	node := p.keyNode[key]
-	frequency := node.Value.(LFUItem).frequency
+	bucket := p.bucket(node.Value.(LFUItem).frequency)
 
-	p.freqList[frequency].Remove(node)
+	p.freqList[bucket].Remove(node)
 	delete(p.keyNode, key)
 
-	if p.freqList[frequency].Len() == 0 {
-		delete(p.freqList, frequency)
-		if p.minFrequency == frequency {
+	if p.freqList[bucket].Len() == 0 {
+		delete(p.freqList, bucket)
+		if p.minFrequency == bucket {
 			p.minFrequency++
 		}
 	}
 
 	return node
 }
+
+// bucket returns the frequency list holding keys of the given frequency, the frequency itself
+// unless logarithmic buckets are enabled
+func (p *LFUPolicy) bucket(frequency Frequency) Frequency {
+	if !p.logBuckets {
+		return frequency
+	}
+	bucket := Frequency(1)
+	for bucket*2 <= frequency {
+		bucket *= 2
+	}
+	return bucket
+}
+
+// Buckets returns the number of frequency lists in use
+func (p *LFUPolicy) Buckets() int {
+	return len(p.freqList)
+}
+
+func (p *LFUPolicy) Frequency(key CacheKey) (Frequency, bool) {
+	node, ok := p.keyNode[key]
+	if !ok {
+		return 0, false
+	}
+	return node.Value.(LFUItem).frequency, true
+}
+
+func (p *LFUPolicy) Histogram() map[Frequency]int {
+	histogram := make(map[Frequency]int, len(p.freqList))
+	if p.logBuckets {
+		for _, node := range p.keyNode {
+			histogram[node.Value.(LFUItem).frequency]++
+		}
+		return histogram
+	}
+	for frequency, fList := range p.freqList {
+		histogram[frequency] = fList.Len()
+	}
+	return histogram
+}
@@ -1,6 +1,11 @@
 package cache
 
-import "testing"
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
 
 // test is a helper that accepts an slice of operations (e.g. [["Put", "foo", "bar"], ["Get", "foo", "bar"]]) and test the behavior
 func test(t *testing.T, cache *Cache, operations [][]interface{}) {
@@ -71,6 +76,25 @@ func TestLRUPolicy(t *testing.T) {
 	test(t, cache, testCase)
 }
 
+func TestMRUPolicy(t *testing.T) {
+	testCase := [][]interface{}{
+		{"Put", "1", "1"},
+		{"Put", "2", "2"},
+		{"Put", "3", "3"},
+		{"Get", "1", "1"},
+		{"Put", "4", "4"}, // 1 is evicted
+		{"Get", "1", nil},
+		{"Put", "5", "5"}, // 4 is evicted
+		{"Get", "4", nil},
+		{"Get", "2", "2"},
+		{"Get", "3", "3"},
+		{"Get", "5", "5"},
+	}
+
+	cache := NewCache(3, MRU)
+	test(t, cache, testCase)
+}
+
 func TestLFUPolicy(t *testing.T) {
 	var cache *Cache
 	var testCase [][]interface{}
@@ -128,3 +152,398 @@ func TestClockPolicy(t *testing.T) {
 	cache := NewCache(5, CLOCK)
 	test(t, cache, testCase)
 }
+
+func TestDelete(t *testing.T) {
+	for _, policy := range []PolicyType{FIFO, LRU, LFU, CLOCK, SLRU, LIRS, GDSF, MRU, RANDOM} {
+		cache := NewCache(2, policy)
+		cache.Put("1", "1")
+		cache.Put("2", "2")
+		cache.Delete("1")
+		cache.Delete("missing")
+		if _, err := cache.Get("1"); err == nil {
+			t.Errorf("policy = %d, key = 1 should be deleted", policy)
+		}
+		cache.Put("3", "3") // fills the free slot, nothing is evicted
+		test(t, cache, [][]interface{}{
+			{"Get", "2", "2"},
+			{"Get", "3", "3"},
+		})
+	}
+}
+
+func TestDeleteFunc(t *testing.T) {
+	cache := NewCache(5, LRU)
+	cache.Put("1", "1")
+	cache.Put("2", "2")
+	version := cache.NewEpoch()
+	cache.Put("2", "two")
+	cache.Put("3", "3")
+	cache.PutWithSource("4", "4", "warmup")
+
+	deleted := cache.DeleteFunc(func(key CacheKey, info EntryInfo) bool {
+		return info.Epoch < version || info.Source == "warmup"
+	})
+	if deleted != 2 {
+		t.Errorf("deleted = %d, but want 2", deleted)
+	}
+	test(t, cache, [][]interface{}{
+		{"Get", "1", nil},
+		{"Get", "2", "two"},
+		{"Get", "3", "3"},
+		{"Get", "4", nil},
+	})
+}
+
+func TestTombstone(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(5, LRU)
+	cache.now = func() time.Time { return now }
+	cache.SetTombstoneGrace(time.Minute)
+
+	cache.Put("1", "1")
+	cache.Put("2", "2")
+	cache.Delete("1")
+	if _, err := cache.Get("1"); err == nil {
+		t.Errorf("key = 1 should be hidden from Get once deleted")
+	}
+	value, tombstoned, err := cache.Peek("1")
+	if err != nil || *value != "1" || !tombstoned {
+		t.Errorf("key = 1 should be visible to Peek as a tombstone")
+	}
+
+	cache.Delete("2")
+	cache.Put("2", "late") // late writer lifts the tombstone
+	if cache.LateWrites() != 1 {
+		t.Errorf("late writes should be 1, but got %d", cache.LateWrites())
+	}
+	test(t, cache, [][]interface{}{{"Get", "2", "late"}})
+
+	now = now.Add(time.Minute)
+	if cache.size != 2 {
+		t.Errorf("key = 1 should still be resident until it is touched")
+	}
+	// Peek touches key = 1, it reports the tombstone a last time and removes it
+	if _, tombstoned, err := cache.Peek("1"); err != nil || !tombstoned {
+		t.Errorf("key = 1 should be reported as a tombstone when touched")
+	}
+	if _, _, err := cache.Peek("1"); err == nil {
+		t.Errorf("key = 1 should be removed after the grace period")
+	}
+	if cache.size != 1 {
+		t.Errorf("size should be 1, but got %d", cache.size)
+	}
+}
+
+func TestNewEpoch(t *testing.T) {
+	cache := NewCache(5, LRU)
+	cache.Put("1", "1")
+	cache.Put("2", "2")
+	cache.NewEpoch()
+	cache.Put("2", "two")
+	cache.Put("3", "3")
+	test(t, cache, [][]interface{}{
+		{"Get", "1", nil},
+		{"Get", "2", "two"},
+		{"Get", "3", "3"},
+	})
+	if cache.size != 2 {
+		t.Errorf("size should be 2, but got %d", cache.size)
+	}
+}
+
+func TestAccessCount(t *testing.T) {
+	cache := NewCache(5, LFU)
+	cache.Put("1", "1")
+	cache.Put("2", "2")
+	cache.Put("3", "3")
+	cache.Get("1")
+	cache.Get("1")
+	cache.Get("2")
+
+	if count, err := cache.AccessCount("1"); err != nil || count != 3 {
+		t.Errorf("access count of key = 1 should be 3, but got %d", count)
+	}
+	if _, err := cache.AccessCount("4"); err == nil {
+		t.Errorf("access count of key = 4 should fail")
+	}
+	histogram, _ := cache.FrequencyHistogram()
+	if !reflect.DeepEqual(histogram, map[Frequency]int{1: 1, 2: 1, 3: 1}) {
+		t.Errorf("histogram should be map[1:1 2:1 3:1], but got %v", histogram)
+	}
+
+	if _, err := NewCache(5, LRU).AccessCount("1"); err == nil {
+		t.Errorf("access count should fail for policies without frequencies")
+	}
+}
+
+func TestParents(t *testing.T) {
+	origin := NewCache(5, LRU)
+	origin.Put("1", "origin")
+	origin.Put("2", "origin")
+	regional := NewCache(5, LRU)
+	regional.Put("1", "regional")
+	regional.AddParent(origin)
+
+	edge := NewCache(5, LRU)
+	edge.AddParent(regional)
+	test(t, edge, [][]interface{}{
+		{"Get", "1", "regional"},
+		{"Get", "2", "origin"},
+		{"Get", "3", nil},
+	})
+
+	if _, _, err := edge.Peek("2"); err != nil {
+		t.Errorf("parent hit should be stored locally")
+	}
+	if edge.Stats().ParentHits != 2 || edge.Stats().Misses != 3 {
+		t.Errorf("stats should count 2 parent hits and 3 misses, but got %v", edge.Stats())
+	}
+}
+
+func TestLFUMaxFrequency(t *testing.T) {
+	cache := NewCacheWithPolicy(2, NewLFUPolicy(WithMaxFrequency(3)))
+	cache.Put("1", "1")
+	for i := 0; i < 10; i++ {
+		cache.Get("1")
+	}
+	cache.Put("2", "2")
+	for i := 0; i < 3; i++ {
+		cache.Get("2")
+	}
+
+	if count, _ := cache.AccessCount("1"); count != 3 {
+		t.Errorf("access count of key = 1 should be capped at 3, but got %d", count)
+	}
+	test(t, cache, [][]interface{}{
+		{"Put", "3", "3"}, // 1 and 2 tie at the cap, 1 was accessed less recently
+		{"Get", "1", nil},
+		{"Get", "2", "2"},
+	})
+}
+
+func TestLFUHalving(t *testing.T) {
+	cache := NewCacheWithPolicy(3, NewLFUPolicy(WithHalving(7)))
+	cache.Put("1", "1")
+	cache.Put("2", "2")
+	for i := 0; i < 5; i++ {
+		cache.Get("1")
+	}
+	cache.Get("2")
+	cache.Get("2") // 7th access, 1: 6 -> 3, 2: 3 -> 1
+
+	histogram, _ := cache.FrequencyHistogram()
+	if !reflect.DeepEqual(histogram, map[Frequency]int{1: 1, 3: 1}) {
+		t.Errorf("histogram should be map[1:1 3:1], but got %v", histogram)
+	}
+	test(t, cache, [][]interface{}{
+		{"Put", "3", "3"},
+		{"Put", "4", "4"}, // 2 and 3 tie at 1, 2 is older
+		{"Get", "2", nil},
+		{"Get", "3", "3"},
+		{"Get", "1", "1"},
+	})
+}
+
+func TestLFUResize(t *testing.T) {
+	cache := NewCache(4, LFU)
+	for _, key := range []CacheKey{"1", "2", "3", "4"} {
+		cache.Put(key, string(key))
+	}
+	cache.Get("4")
+	cache.Delete("1")
+	cache.Resize(1) // evicts across frequencies
+	test(t, cache, [][]interface{}{
+		{"Get", "4", "4"},
+		{"Get", "2", nil},
+		{"Get", "3", nil},
+	})
+}
+
+func TestLFUHalfLife(t *testing.T) {
+	now := time.Now()
+	policy := NewLFUPolicy(WithHalfLife(time.Hour)).(*LFUPolicy)
+	policy.now = func() time.Time { return now }
+	policy.lastDecay = now
+	cache := NewCacheWithPolicy(2, policy)
+
+	cache.Put("1", "1")
+	for i := 0; i < 7; i++ {
+		cache.Get("1") // 1: 8
+	}
+	now = now.Add(2*time.Hour + time.Minute)
+	cache.Put("2", "2")
+	cache.Get("2") // 1: 8 -> 2 after two half-lives, 2: 2
+	if count, _ := cache.AccessCount("1"); count != 2 {
+		t.Errorf("access count of key = 1 should decay to 2, but got %d", count)
+	}
+
+	cache.Get("2")
+	test(t, cache, [][]interface{}{
+		{"Put", "3", "3"}, // 1 is no longer the most popular
+		{"Get", "1", nil},
+		{"Get", "2", "2"},
+	})
+}
+
+func TestLFUDecay(t *testing.T) {
+	now := time.Now()
+	policy := NewLFUPolicy(WithDecay(time.Hour, 0.25)).(*LFUPolicy)
+	policy.now = func() time.Time { return now }
+	policy.lastDecay = now
+	cache := NewCacheWithPolicy(2, policy)
+
+	cache.Put("1", "1")
+	for i := 0; i < 15; i++ {
+		cache.Get("1") // 1: 16
+	}
+	now = now.Add(time.Hour)
+	cache.Get("1") // 1: 16 -> 4, then 5
+	if count, _ := cache.AccessCount("1"); count != 5 {
+		t.Errorf("access count of key = 1 should decay to 5, but got %d", count)
+	}
+	now = now.Add(3 * time.Hour)
+	cache.Put("2", "2")
+	cache.Get("2") // 1: 5 -> 1, 2: 2
+	if count, _ := cache.AccessCount("1"); count != 1 {
+		t.Errorf("access count of key = 1 should not decay below 1, but got %d", count)
+	}
+}
+
+func TestLFUGentleDecay(t *testing.T) {
+	now := time.Now()
+	policy := NewLFUPolicy(WithDecay(time.Hour, 0.99)).(*LFUPolicy)
+	policy.now = func() time.Time { return now }
+	policy.lastDecay = now
+	policy.Add("1")
+	policy.Access("1")
+
+	for _, step := range []struct {
+		hours     int
+		frequency Frequency
+	}{{1, 2}, {19, 2}, {20, 1}} { // 2 * 0.99^40 is about 1.34
+		now = now.Add(time.Duration(step.hours) * time.Hour)
+		policy.decay()
+		if frequency, _ := policy.Frequency("1"); frequency != step.frequency {
+			t.Errorf("frequency of key = 1 should be %d, but got %d", step.frequency, frequency)
+		}
+	}
+}
+
+func TestLFUDecayInvalid(t *testing.T) {
+	for _, factor := range []float64{0, 1, -0.5, 1.5, math.NaN()} {
+		policy := NewLFUPolicy(WithDecay(time.Hour, factor)).(*LFUPolicy)
+		if policy.decayEvery != 0 {
+			t.Errorf("factor = %v should be rejected", factor)
+		}
+	}
+	if policy := NewLFUPolicy(WithDecay(0, 0.5)).(*LFUPolicy); policy.decayEvery != 0 {
+		t.Errorf("interval = 0 should be rejected")
+	}
+}
+
+func TestEvictN(t *testing.T) {
+	cache := NewCache(5, LRU)
+	for _, key := range []CacheKey{"1", "2", "3", "4"} {
+		cache.Put(key, string(key))
+	}
+	cache.Get("1")
+
+	evicted := cache.EvictN(2)
+	expected := []Entry{{"2", "2"}, {"3", "3"}}
+	if !reflect.DeepEqual(evicted, expected) {
+		t.Errorf("evicted should be %v, but got %v", expected, evicted)
+	}
+	if evicted := cache.EvictN(5); len(evicted) != 2 || cache.size != 0 {
+		t.Errorf("the 2 remaining entries should be evicted, but got %v", evicted)
+	}
+	if evicted := cache.EvictN(-1); len(evicted) != 0 {
+		t.Errorf("EvictN(-1) should evict nothing, but got %v", evicted)
+	}
+
+	clock := NewCache(3, CLOCK)
+	for _, key := range []CacheKey{"1", "2", "3"} {
+		clock.Put(key, string(key))
+	}
+	if evicted := clock.EvictN(3); len(evicted) != 3 {
+		t.Errorf("CLOCK should evict 3 entries in a row, but got %v", evicted)
+	}
+}
+
+func TestGetNotOlderThan(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(2, LRU)
+	cache.now = func() time.Time { return now }
+	cache.Put("1", "1")
+	cache.Put("2", "2")
+	now = now.Add(time.Minute)
+	cache.Put("2", "two")
+
+	if _, err := cache.GetNotOlderThan("1", 30*time.Second); err == nil {
+		t.Errorf("key = 1 is older than 30s and should be a miss")
+	}
+	if value, err := cache.GetNotOlderThan("2", 30*time.Second); err != nil || *value != "two" {
+		t.Errorf("key = 2 was rewritten and should be fresh")
+	}
+	if value, err := cache.GetNotOlderThan("1", time.Minute); err != nil || *value != "1" {
+		t.Errorf("key = 1 should be served to callers accepting its age")
+	}
+}
+
+func TestCallFlags(t *testing.T) {
+	cache := NewCache(2, LRU)
+	cache.Put("1", "1")
+	cache.Put("2", "2")
+
+	if value, err := cache.GetWithFlags("1", NoRecord); err != nil || *value != "1" {
+		t.Errorf("key = 1 should be read without recording")
+	}
+	cache.GetWithFlags("3", NoRecord)
+	if stats := cache.Stats(); stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("NoRecord reads should not count, but got %v", stats)
+	}
+
+	cache.PutWithFlags("3", "3", NoStore)
+	cache.PutWithFlags("2", "two", NoStore|NoRecord)
+	test(t, cache, [][]interface{}{
+		{"Get", "3", nil},
+		{"Put", "4", "4"}, // 1 is still the least recently used
+		{"Get", "1", nil},
+		{"Get", "2", "two"},
+	})
+
+	parent := NewCache(2, LRU)
+	parent.Put("5", "5")
+	cache.AddParent(parent)
+	cache.GetWithFlags("5", NoStore)
+	if _, _, err := cache.Peek("5"); err == nil {
+		t.Errorf("NoStore reads should not store parent hits")
+	}
+}
+
+func TestLFULogBuckets(t *testing.T) {
+	policy := NewLFUPolicy(WithLogBuckets()).(*LFUPolicy)
+	cache := NewCacheWithPolicy(3, policy)
+	cache.Put("1", "1")
+	cache.Put("2", "2")
+	cache.Put("3", "3")
+	for i := 0; i < 6; i++ {
+		cache.Get("1") // 1: 7
+	}
+	for i := 0; i < 4; i++ {
+		cache.Get("2") // 2: 5
+	}
+
+	if policy.Buckets() != 2 {
+		t.Errorf("frequencies 1, 5 and 7 should fit in 2 buckets, but got %d", policy.Buckets())
+	}
+	histogram, _ := cache.FrequencyHistogram()
+	if !reflect.DeepEqual(histogram, map[Frequency]int{1: 1, 5: 1, 7: 1}) {
+		t.Errorf("histogram should keep exact frequencies, but got %v", histogram)
+	}
+	test(t, cache, [][]interface{}{
+		{"Put", "4", "4"}, // 3 is the least frequently used
+		{"Get", "3", nil},
+		{"Get", "1", "1"},
+		{"Get", "2", "2"},
+	})
+}
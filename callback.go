@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"context"
+	"runtime/pprof"
+	"sync"
+	"sync/atomic"
+)
+
+// EvictionCallback is called with every entry evicted by the policy
+type EvictionCallback func(key CacheKey, value string)
+
+// PanicMode decides what happens when a user callback panics
+type PanicMode int
+
+const (
+	// PanicLog recovers, counts the panic in Stats and reports it to the Logger
+	PanicLog PanicMode = iota
+	// PanicCount recovers and counts the panic in Stats
+	PanicCount
+	// PanicPropagate lets the panic unwind through the cache, or crash the process when the
+	// callback runs on a worker
+	PanicPropagate
+)
+
+// CallbackMode decides where callbacks run
+type CallbackMode int
+
+const (
+	// CallbackSync runs callbacks inline, extending the time spent in Put
+	CallbackSync CallbackMode = iota
+	// CallbackPool runs callbacks on a pool of workers, in no particular order
+	CallbackPool
+	// CallbackOrdered runs callbacks one at a time on a single worker, in eviction order
+	CallbackOrdered
+)
+
+// SheddingPolicy decides what happens to a callback when the in-flight limit is reached
+type SheddingPolicy int
+
+const (
+	// ShedBlock applies backpressure, Put waits for a callback to complete
+	ShedBlock SheddingPolicy = iota
+	// ShedDrop drops the callback and counts it in Stats
+	ShedDrop
+)
+
+const defaultMaxInFlight = 1024
+
+func (c *Cache) SetOnEvict(callback EvictionCallback) {
+	c.onEvict = callback
+}
+
+func (c *Cache) SetPanicMode(mode PanicMode) {
+	c.panicMode = mode
+}
+
+// runCallback runs a user callback so that a panic can't take down the eviction path
+func (c *Cache) runCallback(callback func()) {
+	if c.panicMode == PanicPropagate {
+		callback()
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			atomic.AddInt64(&c.stats.recoveredPanics, 1)
+			if c.panicMode == PanicLog {
+				c.logger.Error("callback panicked", "panic", r)
+			}
+		}
+	}()
+	callback()
+}
+
+// SetCallbackMode chooses how callbacks run, workers is the pool size for CallbackPool. Switching
+// mode waits for the callbacks queued so far
+func (c *Cache) SetCallbackMode(mode CallbackMode, workers int) {
+	c.callbackMode = mode
+	c.callbackWorkers = workers
+	c.startDispatcher()
+}
+
+// SetCallbackLimit bounds the callbacks queued or running on workers, once the bound is reached
+// shedding decides whether Put waits or the callback is dropped. It has no effect on CallbackSync
+func (c *Cache) SetCallbackLimit(maxInFlight int, shedding SheddingPolicy) {
+	c.maxInFlight = maxInFlight
+	c.shedding = shedding
+	c.startDispatcher()
+}
+
+func (c *Cache) startDispatcher() {
+	c.Close()
+	maxInFlight := c.maxInFlight
+	if maxInFlight < 1 {
+		maxInFlight = defaultMaxInFlight
+	}
+	switch c.callbackMode {
+	case CallbackPool:
+		c.dispatcher = newCallbackDispatcher(c.callbackWorkers, maxInFlight, c.profilerLabels())
+	case CallbackOrdered:
+		c.dispatcher = newCallbackDispatcher(1, maxInFlight, c.profilerLabels())
+	}
+}
+
+// Close waits for the queued callbacks and stops the callback workers, the cache falls back to
+// running callbacks inline
+func (c *Cache) Close() {
+	if c.dispatcher != nil {
+		c.dispatcher.close()
+		c.dispatcher = nil
+	}
+}
+
+func (c *Cache) dispatch(callback func()) {
+	if c.dispatcher == nil {
+		c.runCallback(callback)
+		return
+	}
+
+	select {
+	case c.dispatcher.slots <- struct{}{}:
+	default:
+		if c.shedding == ShedDrop {
+			atomic.AddInt64(&c.stats.droppedCallbacks, 1)
+			return
+		}
+		c.dispatcher.slots <- struct{}{}
+	}
+	c.dispatcher.queue <- func() { c.runCallback(callback) }
+}
+
+// callbackDispatcher runs queued callbacks on a fixed number of workers, a slot is held from the
+// time a callback is queued until it completes
+type callbackDispatcher struct {
+	queue chan func()
+	slots chan struct{}
+	wg    sync.WaitGroup
+}
+
+func newCallbackDispatcher(workers int, maxInFlight int, labels pprof.LabelSet) *callbackDispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+	d := &callbackDispatcher{}
+	d.queue = make(chan func(), maxInFlight)
+	d.slots = make(chan struct{}, maxInFlight)
+	d.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go pprof.Do(context.Background(), labels, func(context.Context) { d.run() })
+	}
+	return d
+}
+
+func (d *callbackDispatcher) run() {
+	defer d.wg.Done()
+	for callback := range d.queue {
+		callback()
+		<-d.slots
+	}
+}
+
+func (d *callbackDispatcher) close() {
+	close(d.queue)
+	d.wg.Wait()
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"sync"
+	"testing"
+)
+
+func TestOnEvict(t *testing.T) {
+	evicted := []CacheKey{}
+	cache := NewCache(2, FIFO)
+	cache.SetOnEvict(func(key CacheKey, value string) {
+		if string(key) != value {
+			t.Errorf("key = %s, value should be %s, but got %s", key, key, value)
+		}
+		evicted = append(evicted, key)
+	})
+
+	cache.Put("1", "1")
+	cache.Put("2", "2")
+	cache.Put("3", "3")
+	cache.Delete("2") // deletes are not evictions
+	if len(evicted) != 1 || evicted[0] != "1" {
+		t.Errorf("evicted should be [1], but got %v", evicted)
+	}
+}
+
+func TestCallbackPanic(t *testing.T) {
+	var buf bytes.Buffer
+	cache := NewCache(1, FIFO)
+	cache.SetLogger(NewStdLogger(log.New(&buf, "", 0)))
+	cache.SetOnEvict(func(CacheKey, string) { panic("boom") })
+
+	cache.Put("1", "1")
+	cache.Put("2", "2")
+	test(t, cache, [][]interface{}{{"Get", "2", "2"}})
+	if cache.Stats().RecoveredPanics != 1 || buf.String() != "ERROR callback panicked panic=boom\n" {
+		t.Errorf("panic should be recovered and logged, but got %d, %q", cache.Stats().RecoveredPanics, buf.String())
+	}
+
+	cache.SetPanicMode(PanicPropagate)
+	defer func() {
+		if recover() == nil {
+			t.Errorf("panic should propagate")
+		}
+	}()
+	cache.Put("3", "3")
+}
+
+func TestCallbackModes(t *testing.T) {
+	for _, mode := range []CallbackMode{CallbackSync, CallbackPool, CallbackOrdered} {
+		var mu sync.Mutex
+		evicted := []CacheKey{}
+		cache := NewCache(1, FIFO)
+		cache.SetCallbackMode(mode, 4)
+		cache.SetOnEvict(func(key CacheKey, value string) {
+			mu.Lock()
+			evicted = append(evicted, key)
+			mu.Unlock()
+		})
+
+		for i := 0; i < 100; i++ {
+			cache.Put(CacheKey(fmt.Sprint(i)), "")
+		}
+		cache.Close()
+
+		if len(evicted) != 99 {
+			t.Errorf("mode = %d, 99 callbacks should run, but got %d", mode, len(evicted))
+		}
+		if mode == CallbackPool {
+			continue
+		}
+		for i, key := range evicted {
+			if key != CacheKey(fmt.Sprint(i)) {
+				t.Errorf("mode = %d, callbacks should run in eviction order, but got %v", mode, evicted)
+				break
+			}
+		}
+	}
+}
+
+func TestCallbackShedding(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	calls := 0
+	cache := NewCache(1, FIFO)
+	cache.SetCallbackMode(CallbackOrdered, 1)
+	cache.SetCallbackLimit(2, ShedDrop)
+	cache.SetOnEvict(func(CacheKey, string) {
+		<-release
+		mu.Lock()
+		calls++
+		mu.Unlock()
+	})
+
+	for i := 0; i < 6; i++ {
+		cache.Put(CacheKey(fmt.Sprint(i)), "") // 5 evictions, 2 in flight, 3 dropped
+	}
+	close(release)
+	cache.Close()
+
+	if calls != 2 || cache.Stats().DroppedCallbacks != 3 {
+		t.Errorf("2 callbacks should run and 3 be dropped, but got %d and %d", calls, cache.Stats().DroppedCallbacks)
+	}
+}
+
+func TestOnEvictReplacedWhileQueued(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	calls := 0
+	cache := NewCache(1, FIFO)
+	cache.SetCallbackMode(CallbackOrdered, 1)
+	cache.SetOnEvict(func(CacheKey, string) {
+		<-release
+		mu.Lock()
+		calls++
+		mu.Unlock()
+	})
+
+	cache.Put("1", "1")
+	cache.Put("2", "2")
+	cache.Put("3", "3") // 2 callbacks queued behind release
+	cache.SetOnEvict(nil)
+	close(release)
+	cache.Close()
+
+	if calls != 2 || cache.Stats().RecoveredPanics != 0 {
+		t.Errorf("callbacks queued before SetOnEvict should run, but got %d calls", calls)
+	}
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// ErrInjected is returned by ChaosCache for injected failures
+var ErrInjected = errors.New("injected failure")
+
+// ChaosConfig configures the faults injected by ChaosCache
+// Latency is added to every Get and Put
+// MissRate is the probability of a Get reporting a miss for a resident key
+// ErrorRate is the probability of a Get failing with ErrInjected
+// Seed makes the injected faults reproducible
+type ChaosConfig struct {
+	Latency   time.Duration
+	MissRate  float64
+	ErrorRate float64
+	Seed      int64
+}
+
+// ChaosCache decorates a cache with fault injection so applications can test how they degrade,
+// it is meant for tests only. Only the data path is exposed so that every call goes through the
+// injected faults, configure the cache before wrapping it
+type ChaosCache struct {
+	cache  *Cache
+	config ChaosConfig
+	rand   *rand.Rand
+	sleep  func(time.Duration)
+}
+
+func NewChaosCache(cache *Cache, config ChaosConfig) *ChaosCache {
+	chaos := &ChaosCache{}
+	chaos.cache = cache
+	chaos.config = config
+	chaos.rand = rand.New(rand.NewSource(config.Seed))
+	chaos.sleep = time.Sleep
+	return chaos
+}
+
+func (c *ChaosCache) Get(key CacheKey) (*string, error) {
+	if err := c.fault(); err != nil {
+		return nil, err
+	}
+	return c.cache.Get(key)
+}
+
+func (c *ChaosCache) GetWithFlags(key CacheKey, flags CallFlags) (*string, error) {
+	if err := c.fault(); err != nil {
+		return nil, err
+	}
+	return c.cache.GetWithFlags(key, flags)
+}
+
+func (c *ChaosCache) GetNotOlderThan(key CacheKey, maxAge time.Duration) (*string, error) {
+	if err := c.fault(); err != nil {
+		return nil, err
+	}
+	return c.cache.GetNotOlderThan(key, maxAge)
+}
+
+func (c *ChaosCache) Peek(key CacheKey) (value *string, tombstoned bool, err error) {
+	if err := c.fault(); err != nil {
+		return nil, false, err
+	}
+	return c.cache.Peek(key)
+}
+
+func (c *ChaosCache) GetRange(from, to CacheKey) ([]Entry, error) {
+	if err := c.fault(); err != nil {
+		return nil, err
+	}
+	return c.cache.GetRange(from, to)
+}
+
+func (c *ChaosCache) KeysAfter(after CacheKey, limit int) ([]CacheKey, error) {
+	if err := c.fault(); err != nil {
+		return nil, err
+	}
+	return c.cache.KeysAfter(after, limit)
+}
+
+func (c *ChaosCache) Put(key CacheKey, value string) {
+	c.delay()
+	c.cache.Put(key, value)
+}
+
+func (c *ChaosCache) PutWithSource(key CacheKey, value string, source string) {
+	c.delay()
+	c.cache.PutWithSource(key, value, source)
+}
+
+func (c *ChaosCache) PutWithFlags(key CacheKey, value string, flags CallFlags) {
+	c.delay()
+	c.cache.PutWithFlags(key, value, flags)
+}
+
+func (c *ChaosCache) PutWithWriter(key CacheKey, value string, writer string) {
+	c.delay()
+	c.cache.PutWithWriter(key, value, writer)
+}
+
+func (c *ChaosCache) PutWithCost(key CacheKey, value string, cost float64) error {
+	c.delay()
+	return c.cache.PutWithCost(key, value, cost)
+}
+
+func (c *ChaosCache) TryPut(key CacheKey, value string) bool {
+	c.delay()
+	return c.cache.TryPut(key, value)
+}
+
+func (c *ChaosCache) Delete(key CacheKey) {
+	c.delay()
+	c.cache.Delete(key)
+}
+
+func (c *ChaosCache) Stats() Stats {
+	return c.cache.Stats()
+}
+
+// fault delays a read and then fails it with ErrInjected or a miss according to the config
+func (c *ChaosCache) fault() error {
+	c.delay()
+	if c.config.ErrorRate > 0 && c.rand.Float64() < c.config.ErrorRate {
+		return ErrInjected
+	}
+	if c.config.MissRate > 0 && c.rand.Float64() < c.config.MissRate {
+		return errors.New("key not found")
+	}
+	return nil
+}
+
+func (c *ChaosCache) delay() {
+	if c.config.Latency > 0 {
+		c.sleep(c.config.Latency)
+	}
+}
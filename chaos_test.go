@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChaosCache(t *testing.T) {
+	slept := time.Duration(0)
+	cache := NewChaosCache(NewCache(1, LRU), ChaosConfig{
+		Latency:   time.Millisecond,
+		MissRate:  0.3,
+		ErrorRate: 0.2,
+		Seed:      1,
+	})
+	cache.sleep = func(d time.Duration) { slept += d }
+	cache.Put("1", "1")
+
+	hits, misses, failures := 0, 0, 0
+	for i := 0; i < 1000; i++ {
+		_, err := cache.Get("1")
+		switch err {
+		case nil:
+			hits++
+		case ErrInjected:
+			failures++
+		default:
+			misses++
+		}
+	}
+
+	if failures < 150 || failures > 250 || misses < 180 || misses > 300 {
+		t.Errorf("about 200 failures and 240 misses expected, but got %d and %d", failures, misses)
+	}
+	if slept != 1001*time.Millisecond {
+		t.Errorf("every call should be delayed, but slept %v", slept)
+	}
+}
+
+func TestChaosCacheReads(t *testing.T) {
+	cache := NewChaosCache(NewCache(1, LRU), ChaosConfig{ErrorRate: 1})
+	cache.Put("1", "1")
+	if _, err := cache.GetWithFlags("1", 0); err != ErrInjected {
+		t.Errorf("GetWithFlags should fail, but got %v", err)
+	}
+	if _, err := cache.GetNotOlderThan("1", time.Hour); err != ErrInjected {
+		t.Errorf("GetNotOlderThan should fail, but got %v", err)
+	}
+	if _, _, err := cache.Peek("1"); err != ErrInjected {
+		t.Errorf("Peek should fail, but got %v", err)
+	}
+	if _, err := cache.GetRange("0", "2"); err != ErrInjected {
+		t.Errorf("GetRange should fail, but got %v", err)
+	}
+	if _, err := cache.KeysAfter("0", 1); err != ErrInjected {
+		t.Errorf("KeysAfter should fail, but got %v", err)
+	}
+}
+
+func TestChaosCacheWrites(t *testing.T) {
+	slept := time.Duration(0)
+	cache := NewChaosCache(NewCache(2, LRU), ChaosConfig{Latency: time.Millisecond})
+	cache.sleep = func(d time.Duration) { slept += d }
+	cache.PutWithWriter("1", "1", "test")
+	cache.PutWithCost("2", "2", 10)
+	if slept != 2*time.Millisecond {
+		t.Errorf("every write should be delayed, but slept %v", slept)
+	}
+}
@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"container/list"
+	"container/ring"
+	"time"
+)
+
+// compactEntryOverhead is a rough estimate of the map and policy bookkeeping bytes per entry
+const compactEntryOverhead = 128
+
+// CompactablePolicy is implemented by policies that can shrink their internal structures
+type CompactablePolicy interface {
+	Compact()
+}
+
+// Compact removes outdated entries and expired tombstones, then rebuilds the internal maps to the
+// current occupancy since Go maps never shrink. Policies that don't implement CompactablePolicy
+// keep their structures as they are. The ordered index frees its nodes as keys are removed, it has
+// nothing to compact. It returns an estimate of the bytes reclaimed
+func (c *Cache) Compact() int {
+	c.purgeTombstones()
+	for key, meta := range c.meta {
+		if meta.epoch < c.epoch {
+			c.remove(key)
+		}
+	}
+
+	data := make(CacheData, len(c.data))
+	for key, value := range c.data {
+		data[key] = value
+	}
+	c.data = data
+	meta := make(map[CacheKey]*entryMeta, len(c.meta))
+	for key, value := range c.meta {
+		meta[key] = value
+	}
+	c.meta = meta
+	tombstones := make(map[CacheKey]time.Time, len(c.tombstones))
+	for key, expiry := range c.tombstones {
+		tombstones[key] = expiry
+	}
+	c.tombstones = tombstones
+	c.slots = append(make([]CacheKey, 0, len(c.slots)), c.slots...)
+	c.unpublish() // the next View rebuilds its buckets to size
+	for policy := c.policy; policy != nil; {
+		if compactable, ok := policy.(CompactablePolicy); ok {
+			compactable.Compact()
+		}
+		layer, ok := policy.(policyLayer)
+		if !ok {
+			break
+		}
+		policy = layer.unwrap() // each middleware compacts its own state
+	}
+
+	reclaimed := (c.peakSize - c.size) * compactEntryOverhead
+	c.peakSize = c.size
+	return reclaimed
+}
+
+func compactElements(keyNode map[CacheKey]*list.Element) map[CacheKey]*list.Element {
+	compacted := make(map[CacheKey]*list.Element, len(keyNode))
+	for key, node := range keyNode {
+		compacted[key] = node
+	}
+	return compacted
+}
+
+func (p *FIFOPolicy) Compact() {
+	p.keyNode = compactElements(p.keyNode)
+}
+
+func (p *LRUPolicy) Compact() {
+	p.keyNode = compactElements(p.keyNode)
+}
+
+func (p *MRUPolicy) Compact() {
+	p.keyNode = compactElements(p.keyNode)
+}
+
+func (p *LFUPolicy) Compact() {
+	p.keyNode = compactElements(p.keyNode)
+	freqList := make(map[Frequency]*list.List, len(p.freqList))
+	for frequency, fList := range p.freqList {
+		freqList[frequency] = fList
+	}
+	p.freqList = freqList
+}
+
+func (p *GDSFPolicy) Compact() {
+	keyItem := make(map[CacheKey]*gdsfItem, len(p.keyItem))
+	for key, item := range p.keyItem {
+		keyItem[key] = item
+	}
+	p.keyItem = keyItem
+	p.heap = append(make(gdsfHeap, 0, len(p.heap)), p.heap...)
+}
+
+// Compact shrinks the key index, the sketch has a fixed size
+func (p *SketchLFUPolicy) Compact() {
+	p.keyNode = compactElements(p.keyNode)
+}
+
+func (p *SLRUPolicy) Compact() {
+	p.keyNode = compactElements(p.keyNode)
+}
+
+func (p *LIRSPolicy) Compact() {
+	entries := make(map[CacheKey]*lirsEntry, len(p.entries))
+	for key, entry := range p.entries {
+		entries[key] = entry
+	}
+	p.entries = entries
+}
+
+func (p *RandomPolicy) Compact() {
+	keyIndex := make(map[CacheKey]int, len(p.keyIndex))
+	for key, index := range p.keyIndex {
+		keyIndex[key] = index
+	}
+	p.keyIndex = keyIndex
+	p.keys = append([]CacheKey(nil), p.keys...)
+}
+
+func (p *ClockPolicy) Compact() {
+	keyNode := make(map[CacheKey]*ring.Ring, len(p.keyNode))
+	for key, node := range p.keyNode {
+		keyNode[key] = node
+	}
+	p.keyNode = keyNode
+}
+
+func (p *pinningPolicy) Compact() {
+	pinned := make(map[CacheKey]bool, len(p.pinned))
+	for key := range p.pinned {
+		pinned[key] = true
+	}
+	p.pinned = pinned
+	resident := make(map[CacheKey]bool, len(p.resident))
+	for key := range p.resident {
+		resident[key] = true
+	}
+	p.resident = resident
+}
+
+func (p *scanBypassPolicy) Compact() {
+	p.keyNode = compactElements(p.keyNode)
+}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestCompact(t *testing.T) {
+	for _, policy := range []PolicyType{FIFO, LRU, LFU, CLOCK, SLRU, LIRS, GDSF, MRU, RANDOM} {
+		cache := NewCache(100, policy)
+		for i := 0; i < 100; i++ {
+			cache.Put(CacheKey(fmt.Sprint(i)), fmt.Sprint(i))
+		}
+		if _, ok := cache.policy.(CompactablePolicy); !ok {
+			t.Errorf("policy = %d should implement CompactablePolicy", policy)
+		}
+		cache.NewEpoch()
+		cache.Put("0", "0")
+		cache.Put("1", "1")
+
+		if reclaimed := cache.Compact(); reclaimed != 98*compactEntryOverhead {
+			t.Errorf("policy = %d, reclaimed should be %d, but got %d", policy, 98*compactEntryOverhead, reclaimed)
+		}
+		if cache.size != 2 || len(cache.data) != 2 || len(cache.meta) != 2 {
+			t.Errorf("policy = %d, 2 entries should remain, but got %d", policy, cache.size)
+		}
+		if cap(cache.slots) != 2 {
+			t.Errorf("policy = %d, slots should be shrunk to 2, but have a capacity of %d", policy, cap(cache.slots))
+		}
+		if reclaimed := cache.Compact(); reclaimed != 0 {
+			t.Errorf("policy = %d, nothing should be reclaimed twice, but got %d", policy, reclaimed)
+		}
+		test(t, cache, [][]interface{}{
+			{"Put", "2", "2"},
+			{"Get", "0", "0"},
+			{"Get", "1", "1"},
+			{"Get", "2", "2"},
+			{"Get", "3", nil},
+		})
+	}
+}
+
+func TestCompactThroughMiddleware(t *testing.T) {
+	lru := NewLRUPolicy()
+	policy := WrapPolicy(lru, WithPinning(), WithTracing(func(PolicyEvent) {}))
+	cache := NewCacheWithPolicy(100, policy)
+	for i := 0; i < 100; i++ {
+		cache.Put(CacheKey(fmt.Sprint(i)), fmt.Sprint(i))
+	}
+	for i := 0; i < 98; i++ {
+		cache.Delete(CacheKey(fmt.Sprint(i)))
+	}
+	keyNode := lru.(*LRUPolicy).keyNode
+	cache.Compact()
+	if reflect.ValueOf(lru.(*LRUPolicy).keyNode).Pointer() == reflect.ValueOf(keyNode).Pointer() {
+		t.Errorf("the wrapped policy should be compacted")
+	}
+	test(t, cache, [][]interface{}{
+		{"Put", "100", "100"},
+		{"Get", "98", "98"},
+		{"Get", "99", "99"},
+		{"Get", "100", "100"},
+	})
+}
+
+func TestCompactSketchLFU(t *testing.T) {
+	cache := NewCacheWithPolicy(100, NewSketchLFUPolicy(64))
+	for i := 0; i < 100; i++ {
+		cache.Put(CacheKey(fmt.Sprint(i)), fmt.Sprint(i))
+	}
+	for i := 0; i < 99; i++ {
+		cache.Delete(CacheKey(fmt.Sprint(i)))
+	}
+	keyNode := cache.policy.(*SketchLFUPolicy).keyNode
+	cache.Compact()
+	if reflect.ValueOf(cache.policy.(*SketchLFUPolicy).keyNode).Pointer() == reflect.ValueOf(keyNode).Pointer() {
+		t.Errorf("the key index should be rebuilt")
+	}
+	test(t, cache, [][]interface{}{
+		{"Get", "99", "99"},
+	})
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NewKey builds a composite key from parts. Each part is encoded as a type tag, its length and
+// its text, e.g. NewKey("user", 42) is "s4:useri2:42", so keys are unambiguous whatever the parts
+// contain, a string "42" and an int 42 differ, and the keys built from a list of leading parts all
+// share NewKey of those parts as prefix, see DeletePrefix
+func NewKey(parts ...interface{}) CacheKey {
+	var key strings.Builder
+	for _, part := range parts {
+		tag, text := encodePart(part)
+		key.WriteByte(tag)
+		key.WriteString(strconv.Itoa(len(text)))
+		key.WriteByte(':')
+		key.WriteString(text)
+	}
+	return CacheKey(key.String())
+}
+
+func encodePart(part interface{}) (byte, string) {
+	switch p := part.(type) {
+	case string:
+		return 's', p
+	case CacheKey:
+		return 's', string(p)
+	case int, int8, int16, int32, int64:
+		return 'i', fmt.Sprint(p)
+	case uint, uint8, uint16, uint32, uint64:
+		return 'u', fmt.Sprint(p)
+	case float32, float64:
+		return 'f', fmt.Sprint(p)
+	case bool:
+		return 'b', strconv.FormatBool(p)
+	default:
+		return 'v', fmt.Sprint(p)
+	}
+}
+
+// KeyParts decodes a key built by NewKey into the text of its parts
+func KeyParts(key CacheKey) ([]string, error) {
+	parts := []string{}
+	rest := string(key)
+	for len(rest) > 0 {
+		colon := strings.IndexByte(rest, ':')
+		if colon < 2 || !strings.ContainsRune("siufbv", rune(rest[0])) {
+			return nil, errors.New("not a composite key")
+		}
+		if strings.TrimLeft(rest[1:colon], "0123456789") != "" {
+			return nil, errors.New("not a composite key") // Atoi would take a sign
+		}
+		length, err := strconv.Atoi(rest[1:colon])
+		if err != nil || colon+1+length > len(rest) {
+			return nil, errors.New("not a composite key")
+		}
+		parts = append(parts, rest[colon+1:colon+1+length])
+		rest = rest[colon+1+length:]
+	}
+	return parts, nil
+}
+
+// DeletePrefix deletes every key built by NewKey whose leading parts are parts, it returns the
+// number of keys deleted. The prefix goes through the key transformer like the keys did, which
+// only makes sense for transformers preserving prefixes, e.g. not HashLongKeys
+func (c *Cache) DeletePrefix(parts ...interface{}) int {
+	prefix := string(c.canonical(NewKey(parts...)))
+	deleted := 0
+	for key := range c.data {
+		if _, ok := c.tombstones[key]; !ok && strings.HasPrefix(string(key), prefix) {
+			c.Delete(key)
+			deleted++
+		}
+	}
+	return deleted
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewKey(t *testing.T) {
+	if NewKey("user", 42) != "s4:useri2:42" {
+		t.Errorf("key should be s4:useri2:42, but got %s", NewKey("user", 42))
+	}
+	if NewKey("a:b", "c") == NewKey("a", "b:c") || NewKey("42") == NewKey(42) {
+		t.Errorf("keys should be unambiguous")
+	}
+
+	parts, err := KeyParts(NewKey("user", 42, "a:b", true))
+	if err != nil || !reflect.DeepEqual(parts, []string{"user", "42", "a:b", "true"}) {
+		t.Errorf("parts should be [user 42 a:b true], but got %v, %v", parts, err)
+	}
+	if _, err := KeyParts("user:42"); err == nil {
+		t.Errorf("plain keys should not decode")
+	}
+	for _, key := range []CacheKey{"s+1:a", "s-1:a", "s 1:a"} {
+		if _, err := KeyParts(key); err == nil {
+			t.Errorf("key = %q should not decode, lengths are plain digits", key)
+		}
+	}
+}
+
+func TestDeletePrefix(t *testing.T) {
+	cache := NewCache(5, LRU)
+	cache.Put(NewKey("user", 1, "profile"), "1")
+	cache.Put(NewKey("user", 1, "settings"), "1")
+	cache.Put(NewKey("user", 12, "profile"), "12")
+	cache.Put(NewKey("team", 1), "1")
+
+	if deleted := cache.DeletePrefix("user", 1); deleted != 2 {
+		t.Errorf("2 keys should be deleted, but got %d", deleted)
+	}
+	test(t, cache, [][]interface{}{
+		{"Get", string(NewKey("user", 1, "profile")), nil},
+		{"Get", string(NewKey("user", 12, "profile")), "12"},
+		{"Get", string(NewKey("team", 1)), "1"},
+	})
+}
+
+func TestDeletePrefixCanonical(t *testing.T) {
+	cache := NewCache(5, LRU)
+	cache.SetKeyTransformer(LowercaseKeys)
+	cache.Put(NewKey("User", 1, "profile"), "1")
+	cache.Put(NewKey("team", 1), "1")
+
+	if deleted := cache.DeletePrefix("USER", 1); deleted != 1 {
+		t.Errorf("1 key should be deleted, but got %d", deleted)
+	}
+}
@@ -0,0 +1,58 @@
+package cache
+
+// valuePool interns values of at least minSize bytes so that identical values stored under
+// different keys share one copy, a value is freed once the last key referencing it is gone
+type valuePool struct {
+	minSize int
+	values  map[string]*pooledValue
+	saved   int
+}
+
+type pooledValue struct {
+	value string
+	refs  int
+}
+
+func (p *valuePool) intern(value string) string {
+	if len(value) < p.minSize {
+		return value
+	}
+	if pooled, ok := p.values[value]; ok {
+		pooled.refs++
+		p.saved += len(value)
+		return pooled.value
+	}
+	p.values[value] = &pooledValue{value: value, refs: 1}
+	return value
+}
+
+func (p *valuePool) release(value string) {
+	pooled, ok := p.values[value]
+	if !ok {
+		return
+	}
+	pooled.refs--
+	if pooled.refs == 0 {
+		delete(p.values, value)
+	} else {
+		p.saved -= len(value)
+	}
+}
+
+// EnableValueDedup stores identical values of at least minSize bytes once, which pays off when
+// many keys map to the same default or fallback payload. Values are compared in full on every
+// write, so keep minSize above the size of the typical unique value
+func (c *Cache) EnableValueDedup(minSize int) {
+	c.values = &valuePool{minSize: minSize, values: make(map[string]*pooledValue)}
+	for key, value := range c.data {
+		c.data[key] = c.values.intern(value)
+	}
+}
+
+// DedupSavings returns the number of value bytes not stored thanks to value dedup
+func (c *Cache) DedupSavings() int {
+	if c.values == nil {
+		return 0
+	}
+	return c.values.saved
+}
@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValueDedup(t *testing.T) {
+	fallback := strings.Repeat("x", 100)
+	cache := NewCache(3, FIFO)
+	cache.Put("1", fallback)
+	cache.EnableValueDedup(64)
+	cache.Put("2", strings.Repeat("x", 100))
+	cache.Put("3", "small")
+	cache.Put("3", strings.Repeat("x", 100))
+	if got := cache.DedupSavings(); got != 200 {
+		t.Errorf("savings = %d, but want 200", got)
+	}
+	if value, _ := cache.Get("2"); *value != fallback {
+		t.Errorf("value = %q, but want the fallback", *value)
+	}
+
+	cache.Put("4", "4") // 1 is evicted
+	cache.Delete("2")
+	if got := cache.DedupSavings(); got != 0 {
+		t.Errorf("savings = %d, but want 0", got)
+	}
+	cache.Put("3", "3")
+	if len(cache.values.values) != 0 {
+		t.Errorf("pool should be empty once no key references the value, but has %d values", len(cache.values.values))
+	}
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"sync"
+)
+
+type ChangeKind int
+
+const (
+	// ChangeUpsert carries the new value of a row
+	ChangeUpsert ChangeKind = iota
+	// ChangeDelete reports a deleted row
+	ChangeDelete
+)
+
+// Change is a single event of an external change stream
+type Change struct {
+	Kind  ChangeKind
+	Key   CacheKey
+	Value string
+}
+
+// Feeder is an external change stream, e.g. a Kafka topic or a MySQL binlog adapter. Next
+// blocks until the next change is available and returns an error when the stream ends or ctx
+// is done
+type Feeder interface {
+	Next(ctx context.Context) (Change, error)
+}
+
+// ApplyChange applies a change from an external source: upserts refresh the value of resident
+// entries without touching the policy or the statistics, deletes drop the entry. Keys that
+// aren't resident are ignored, the feed keeps the cache fresh but doesn't decide its contents
+func (c *Cache) ApplyChange(change Change) {
+	switch change.Kind {
+	case ChangeUpsert:
+		c.PutWithFlags(change.Key, change.Value, NoStore|NoRecord)
+	case ChangeDelete:
+		c.Delete(change.Key)
+	}
+}
+
+// Follow applies the changes read from feeder until it returns an error, which Follow returns.
+// lock guards the cache and is held while each change is applied
+func (c *Cache) Follow(ctx context.Context, feeder Feeder, lock sync.Locker) error {
+	for {
+		change, err := feeder.Next(ctx)
+		if err != nil {
+			return err
+		}
+		lock.Lock()
+		c.ApplyChange(change)
+		lock.Unlock()
+	}
+}
@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+type channelFeeder chan Change
+
+func (f channelFeeder) Next(ctx context.Context) (Change, error) {
+	select {
+	case change := <-f:
+		return change, nil
+	case <-ctx.Done():
+		return Change{}, ctx.Err()
+	}
+}
+
+func TestFollow(t *testing.T) {
+	cache := NewCache(2, LRU)
+	cache.Put("1", "1")
+	cache.Put("2", "2")
+
+	var mu sync.Mutex
+	feeder := make(channelFeeder)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- cache.Follow(ctx, feeder, &mu) }()
+	feeder <- Change{ChangeUpsert, "1", "one"}
+	feeder <- Change{ChangeUpsert, "3", "three"}
+	feeder <- Change{ChangeDelete, "2", ""}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Follow = %v, but want %v", err, context.Canceled)
+	}
+
+	test(t, cache, [][]interface{}{
+		{"Get", "1", "one"},
+		{"Get", "2", nil},
+		{"Get", "3", nil},
+	})
+	if stats := cache.Stats(); stats.Hits != 1 {
+		t.Errorf("hits = %d, but the feed should not record any", stats.Hits)
+	}
+}
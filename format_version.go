@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// formatVersion is the version written by TransferTo and ExportNDJSON. Version 0 are the streams
+// written before headers existed, they are read as such when the header is missing
+const formatVersion = 1
+
+// transferMagic starts a versioned TransferTo stream, 0x80 0x00 is a non-minimal varint that the
+// headerless format, starting with the varint length of a key, never writes
+var transferMagic = []byte{0x80, 0x00, 'c', 'r', 'g'}
+
+// ndjsonFormat identifies the header line of an NDJSON export
+const ndjsonFormat = "cache-replacement-go/ndjson"
+
+// migrations upgrade an entry read in version v to version v+1, a version without a migration
+// can no longer be read. Register one with every format change
+var migrations = map[int]func(*ndjsonEntry) error{
+	0: func(*ndjsonEntry) error { return nil }, // only the header was added
+}
+
+// FormatVersionError is returned when reading a stream whose format version can't be migrated to
+// the current one, either because it is too old or written by a newer release
+type FormatVersionError struct {
+	Version int
+	Current int
+}
+
+func (e *FormatVersionError) Error() string {
+	if e.Version > e.Current {
+		return fmt.Sprintf("format version %d is newer than the supported version %d", e.Version, e.Current)
+	}
+	return fmt.Sprintf("format version %d is too old to be migrated to version %d", e.Version, e.Current)
+}
+
+// checkFormatVersion fails unless entries in version can be migrated to the current version
+func checkFormatVersion(version int) error {
+	if version > formatVersion {
+		return &FormatVersionError{version, formatVersion}
+	}
+	for v := version; v < formatVersion; v++ {
+		if _, ok := migrations[v]; !ok {
+			return &FormatVersionError{version, formatVersion}
+		}
+	}
+	return nil
+}
+
+// migrate upgrades an entry read in version to the current version
+func migrate(entry *ndjsonEntry, version int) error {
+	for v := version; v < formatVersion; v++ {
+		if err := migrations[v](entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeTransferHeader(writer *bufio.Writer) error {
+	if _, err := writer.Write(transferMagic); err != nil {
+		return err
+	}
+	buf := make([]byte, binary.MaxVarintLen64)
+	_, err := writer.Write(buf[:binary.PutUvarint(buf, formatVersion)])
+	return err
+}
+
+// readTransferHeader returns the format version of the stream, 0 when it has no header
+func readTransferHeader(reader *bufio.Reader) (int, error) {
+	magic, err := reader.Peek(len(transferMagic))
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	if string(magic) != string(transferMagic) {
+		return 0, checkFormatVersion(0)
+	}
+	reader.Discard(len(transferMagic))
+	version, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return 0, truncated(err)
+	}
+	return int(version), checkFormatVersion(int(version))
+}
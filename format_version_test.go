@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFormatVersion(t *testing.T) {
+	// streams written before the version header
+	legacy := []byte{1, '1', 1, 'a', 2}
+	cache := NewCache(2, LFU)
+	if received, err := cache.ReceiveFrom(bytes.NewReader(legacy)); err != nil || received != 1 {
+		t.Errorf("headerless stream should be read as version 0, but got %d, %v", received, err)
+	}
+	if imported, err := cache.ImportNDJSON(strings.NewReader(`{"key":"2","value":"b"}` + "\n")); err != nil || imported != 1 {
+		t.Errorf("headerless export should be read as version 0, but got %d, %v", imported, err)
+	}
+	test(t, cache, [][]interface{}{
+		{"Get", "1", "a"},
+		{"Get", "2", "b"},
+	})
+
+	var versionErr *FormatVersionError
+	newer := append(append([]byte(nil), transferMagic...), formatVersion+1)
+	if _, err := cache.ReceiveFrom(bytes.NewReader(newer)); !errors.As(err, &versionErr) || versionErr.Version != formatVersion+1 {
+		t.Errorf("newer stream should fail with a FormatVersionError, but got %v", err)
+	}
+	header := `{"format":"` + ndjsonFormat + `","version":-1}` + "\n"
+	if _, err := cache.ImportNDJSON(strings.NewReader(header)); !errors.As(err, &versionErr) || versionErr.Version != -1 {
+		t.Errorf("too old export should fail with a FormatVersionError, but got %v", err)
+	}
+
+	upgrade := migrations[0]
+	defer func() { migrations[0] = upgrade }()
+	migrations[0] = func(entry *ndjsonEntry) error {
+		entry.Value = strings.ToUpper(entry.Value)
+		return nil
+	}
+	cache.ReceiveFrom(bytes.NewReader([]byte{1, '3', 1, 'c', 0}))
+	test(t, cache, [][]interface{}{
+		{"Get", "3", "C"},
+	})
+	delete(migrations, 0)
+	if _, err := cache.ReceiveFrom(bytes.NewReader(legacy)); !errors.As(err, &versionErr) || versionErr.Version != 0 {
+		t.Errorf("stream without a migration should fail with a FormatVersionError, but got %v", err)
+	}
+}
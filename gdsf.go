@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"container/heap"
+	"errors"
+)
+
+// GDSF, GreedyDual-Size-Frequency: each key has the priority L + frequency * cost / size and the
+// key with the lowest priority is evicted, setting L to its priority. Small, costly and popular
+// keys are kept, and L ages the priorities of keys that stopped being accessed. The cache passes
+// the length of the value as size and the cost given to PutWithCost, 1 by default
+type GDSFPolicy struct {
+	heap      gdsfHeap
+	keyItem   map[CacheKey]*gdsfItem
+	inflation float64 // L
+}
+
+type gdsfItem struct {
+	key       CacheKey
+	frequency int
+	size      int
+	cost      float64
+	priority  float64
+	index     int
+}
+
+// CostAwarePolicy is implemented by policies that weigh keys by size and cost, the cache calls
+// SetCost after every write with the size of the value and the cost given to PutWithCost
+type CostAwarePolicy interface {
+	SetCost(key CacheKey, size int, cost float64)
+}
+
+func NewGDSFPolicy() CachePolicy {
+	policy := &GDSFPolicy{}
+	policy.keyItem = make(map[CacheKey]*gdsfItem)
+	return policy
+}
+
+func (p *GDSFPolicy) Victim() CacheKey {
+	key := p.heap[0].key
+	p.Evict(key)
+	return key
+}
+
+// Evict removes key and raises L to its priority
+func (p *GDSFPolicy) Evict(key CacheKey) {
+	item, ok := p.keyItem[key]
+	if !ok {
+		return
+	}
+	p.inflation = item.priority
+	p.Remove(key)
+}
+
+func (p *GDSFPolicy) Add(key CacheKey) {
+	item := &gdsfItem{key: key, frequency: 1, size: 1, cost: 1}
+	item.priority = p.priority(item)
+	p.keyItem[key] = item
+	heap.Push(&p.heap, item)
+}
+
+func (p *GDSFPolicy) Remove(key CacheKey) {
+	item, ok := p.keyItem[key]
+	if !ok {
+		return
+	}
+	heap.Remove(&p.heap, item.index)
+	delete(p.keyItem, key)
+}
+
+func (p *GDSFPolicy) Access(key CacheKey) {
+	item, ok := p.keyItem[key]
+	if !ok {
+		return
+	}
+	item.frequency++
+	p.update(item)
+}
+
+func (p *GDSFPolicy) SetCost(key CacheKey, size int, cost float64) {
+	item, ok := p.keyItem[key]
+	if !ok {
+		return
+	}
+	if size < 1 {
+		size = 1
+	}
+	item.size = size
+	item.cost = cost
+	p.update(item)
+}
+
+func (p *GDSFPolicy) update(item *gdsfItem) {
+	item.priority = p.priority(item)
+	heap.Fix(&p.heap, item.index)
+}
+
+func (p *GDSFPolicy) priority(item *gdsfItem) float64 {
+	return p.inflation + float64(item.frequency)*item.cost/float64(item.size)
+}
+
+// gdsfHeap is a min-heap of priorities, ties are broken by insertion order only approximately
+type gdsfHeap []*gdsfItem
+
+func (h gdsfHeap) Len() int           { return len(h) }
+func (h gdsfHeap) Less(i, j int) bool { return h[i].priority < h[j].priority }
+
+func (h gdsfHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *gdsfHeap) Push(x interface{}) {
+	item := x.(*gdsfItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *gdsfHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return item
+}
+
+// PutWithCost is Put for cost-aware policies such as GDSF, cost is what a miss on key costs, e.g.
+// the fetch latency, and the size of the value is passed along with it. Other policies ignore
+// both, the cost also stays with the entry when it is later overwritten with Put. It fails when
+// key is not stored, e.g. turned down by the admission policy
+func (c *Cache) PutWithCost(key CacheKey, value string, cost float64) error {
+	if cost <= 0 {
+		return errors.New("cost must be positive")
+	}
+	key = c.canonical(key)
+	if !c.put(key, value, "", 0) {
+		return errors.New("key not stored")
+	}
+	c.meta[key].cost = cost
+	c.setCost(key)
+	return nil
+}
+
+// setCost passes the size and cost of a resident key to a cost-aware policy
+func (c *Cache) setCost(key CacheKey) {
+	if policy, ok := basePolicy(c.policy).(CostAwarePolicy); ok {
+		policy.SetCost(key, len(c.data[key]), c.meta[key].cost)
+	}
+}
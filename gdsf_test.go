@@ -0,0 +1,40 @@
+package cache
+
+import "testing"
+
+func TestGDSFPolicy(t *testing.T) {
+	cache := NewCache(3, GDSF)
+	cache.PutWithCost("1", "0123456789", 10) // 10 * 1 / 10 = 1
+	cache.Put("2", "2")                      // 1
+	cache.PutWithCost("3", "3", 5)           // 5
+	test(t, cache, [][]interface{}{
+		{"Get", "2", "2"}, // 2
+		{"Put", "4", "4"}, // 1 is evicted, L = 1, 4: 2
+		{"Get", "1", nil},
+		{"Get", "4", "4"}, // 3
+		{"Put", "5", "5"}, // 2 is evicted, L = 2, 5: 3
+		{"Get", "2", nil},
+		{"Get", "3", "3"},
+		{"Get", "4", "4"},
+		{"Get", "5", "5"},
+	})
+
+	if err := cache.PutWithCost("6", "6", 0); err == nil {
+		t.Errorf("PutWithCost should reject a zero cost")
+	}
+	if err := NewCache(0, GDSF).PutWithCost("6", "6", 1); err == nil {
+		t.Errorf("PutWithCost should fail when the key is not stored")
+	}
+}
+
+func TestGDSFPolicyPrefersSmallCostlyKeys(t *testing.T) {
+	cache := NewCache(2, GDSF)
+	cache.PutWithCost("small", "s", 100)
+	cache.PutWithCost("large", string(make([]byte, 1000)), 100)
+	cache.Get("large")
+	cache.Put("other", "o") // large is evicted, its priority 2 * 100 / 1000 is the lowest
+	test(t, cache, [][]interface{}{
+		{"Get", "large", nil},
+		{"Get", "small", "s"},
+	})
+}
@@ -0,0 +1,24 @@
+package cache
+
+import "time"
+
+// HealthReport describes the state of the cache for health endpoints
+// AsyncQueueDepth is the number of pending policy events when the policy is an AsyncPolicy
+// LastSnapshot is the time of the last Snapshot, zero if none was taken
+type HealthReport struct {
+	Size            int
+	Capacity        int
+	AsyncQueueDepth int
+	LastSnapshot    time.Time
+}
+
+func (c *Cache) Health() HealthReport {
+	report := HealthReport{}
+	report.Size = c.size
+	report.Capacity = c.maxSize
+	if policy, ok := basePolicy(c.policy).(*AsyncPolicy); ok {
+		report.AsyncQueueDepth = policy.QueueDepth()
+	}
+	report.LastSnapshot = c.lastSnapshot
+	return report
+}
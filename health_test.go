@@ -0,0 +1,47 @@
+package cache
+
+import "testing"
+
+func TestHealth(t *testing.T) {
+	policy := NewAsyncPolicy(NewFIFOPolicy(), 8)
+	defer policy.Close()
+	cache := NewCacheWithPolicy(4, policy)
+	cache.Put("1", "1")
+
+	report := cache.Health()
+	if report.Size != 1 || report.Capacity != 4 || !report.LastSnapshot.IsZero() {
+		t.Errorf("unexpected report %v", report)
+	}
+	if report.AsyncQueueDepth < 0 || report.AsyncQueueDepth > 1 {
+		t.Errorf("queue depth should be at most 1, but got %d", report.AsyncQueueDepth)
+	}
+
+	cache.Snapshot()
+	if cache.Health().LastSnapshot.IsZero() {
+		t.Errorf("last snapshot should be recorded")
+	}
+}
+
+// blockingPolicy holds every Add until release is closed
+type blockingPolicy struct {
+	CachePolicy
+	release chan struct{}
+}
+
+func (p blockingPolicy) Add(key CacheKey) {
+	<-p.release
+	p.CachePolicy.Add(key)
+}
+
+func TestHealthThroughMiddleware(t *testing.T) {
+	async := NewAsyncPolicy(blockingPolicy{NewFIFOPolicy(), make(chan struct{})}, 8)
+	cache := NewCacheWithPolicy(4, WrapPolicy(async, WithTracing(func(PolicyEvent) {})))
+	cache.Put("1", "1")
+	cache.Put("2", "2")
+	cache.Put("3", "3") // the first add may be taken off the queue, it blocks the others
+	if depth := cache.Health().AsyncQueueDepth; depth < 2 {
+		t.Errorf("queue depth should be at least 2 through the middleware, but got %d", depth)
+	}
+	close(async.policy.(blockingPolicy).release)
+	async.Close()
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+const hllPrecision = 12 // 4096 registers, about 1.6% standard error
+
+// hyperLogLog estimates the number of distinct keys added. Registers are updated atomically so
+// the estimate can be read while the cache is being written
+type hyperLogLog struct {
+	registers [1 << hllPrecision]uint32
+}
+
+func (h *hyperLogLog) add(key CacheKey) {
+	hash := mixedHash(key)
+	register := &h.registers[hash>>(64-hllPrecision)]
+	rank := uint32(bits.LeadingZeros64(hash<<hllPrecision|1<<(hllPrecision-1)) + 1)
+	for {
+		current := atomic.LoadUint32(register)
+		if rank <= current || atomic.CompareAndSwapUint32(register, current, rank) {
+			return
+		}
+	}
+}
+
+// estimate returns the number of distinct keys added to any of sketches
+func estimate(sketches ...*hyperLogLog) int {
+	const m = 1 << hllPrecision
+	sum, zeros := 0.0, 0
+	for i := 0; i < m; i++ {
+		rank := uint32(0)
+		for _, sketch := range sketches {
+			if r := atomic.LoadUint32(&sketch.registers[i]); r > rank {
+				rank = r
+			}
+		}
+		if rank == 0 {
+			zeros++
+		}
+		sum += math.Ldexp(1, -int(rank))
+	}
+	e := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if e <= 2.5*m && zeros > 0 {
+		// linear counting is more accurate for small cardinalities
+		e = m * math.Log(float64(m)/float64(zeros))
+	}
+	return int(e + 0.5)
+}
+
+// distinctKeys counts the keys requested in the current and the previous window, it is replaced
+// rather than updated on rotation so that Stats can read it without a lock
+type distinctKeys struct {
+	current  *hyperLogLog
+	previous *hyperLogLog
+	rotateAt time.Time
+}
+
+// EnableDistinctKeys estimates the number of distinct keys requested with Get, reported as
+// Stats.DistinctKeys. The estimate covers the last one to two windows and is within a few percent,
+// compare it with the capacity to tell whether the working set fits in the cache. window must be
+// positive
+func (c *Cache) EnableDistinctKeys(window time.Duration) error {
+	if window <= 0 {
+		return errors.New("window must be positive")
+	}
+	c.distinctWindow = window
+	c.distinct.Store(&distinctKeys{&hyperLogLog{}, &hyperLogLog{}, c.now().Add(window)})
+	return nil
+}
+
+func (c *Cache) recordDistinct(key CacheKey) {
+	d, ok := c.distinct.Load().(*distinctKeys)
+	if !ok {
+		return
+	}
+	if now := c.now(); !now.Before(d.rotateAt) {
+		d = &distinctKeys{&hyperLogLog{}, d.current, now.Add(c.distinctWindow)}
+		c.distinct.Store(d)
+	}
+	d.current.add(key)
+}
+
+func (c *Cache) distinctEstimate() int {
+	d, ok := c.distinct.Load().(*distinctKeys)
+	if !ok {
+		return 0
+	}
+	return estimate(d.current, d.previous)
+}
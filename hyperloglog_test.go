@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	for _, n := range []int{10, 1000, 100000} {
+		sketch := &hyperLogLog{}
+		for i := 0; i < n; i++ {
+			sketch.add(CacheKey(fmt.Sprint(i)))
+			sketch.add(CacheKey(fmt.Sprint(i))) // duplicates don't count
+		}
+		got := estimate(sketch)
+		if diff := float64(got-n) / float64(n); diff > 0.05 || diff < -0.05 {
+			t.Errorf("estimate = %d, but want about %d", got, n)
+		}
+	}
+}
+
+func TestDistinctKeys(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(10, LRU)
+	cache.now = func() time.Time { return now }
+	cache.Get("0")
+	if stats := cache.Stats(); stats.DistinctKeys != 0 {
+		t.Errorf("distinct keys = %d, but counting is off", stats.DistinctKeys)
+	}
+
+	if err := cache.EnableDistinctKeys(0); err == nil {
+		t.Errorf("window = 0 should fail")
+	}
+	cache.EnableDistinctKeys(time.Minute)
+	for i := 0; i < 100; i++ {
+		cache.Get(CacheKey(fmt.Sprint(i % 50)))
+	}
+	if stats := cache.Stats(); stats.DistinctKeys != 50 {
+		t.Errorf("distinct keys = %d, but want 50", stats.DistinctKeys)
+	}
+
+	now = now.Add(time.Minute)
+	cache.Get("50")
+	if stats := cache.Stats(); stats.DistinctKeys != 51 {
+		t.Errorf("distinct keys = %d, but the previous window should still count", stats.DistinctKeys)
+	}
+	now = now.Add(time.Minute)
+	cache.Get("51")
+	if stats := cache.Stats(); stats.DistinctKeys != 2 {
+		t.Errorf("distinct keys = %d, but want 2", stats.DistinctKeys)
+	}
+}
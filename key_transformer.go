@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// KeyTransformer canonicalizes keys, it is applied to the key of every call on the cache so that
+// call sites can't create duplicate entries for the same logical key. It must be idempotent
+type KeyTransformer func(CacheKey) CacheKey
+
+func (c *Cache) SetKeyTransformer(transformer KeyTransformer) {
+	c.keyTransformer = transformer
+	c.unpublish()
+}
+
+func (c *Cache) canonical(key CacheKey) CacheKey {
+	if c.keyTransformer == nil {
+		return key
+	}
+	return c.keyTransformer(key)
+}
+
+// LowercaseKeys is a KeyTransformer lowering the case of keys
+func LowercaseKeys(key CacheKey) CacheKey {
+	return CacheKey(strings.ToLower(string(key)))
+}
+
+// TrimKeys is a KeyTransformer removing leading and trailing white space from keys
+func TrimKeys(key CacheKey) CacheKey {
+	return CacheKey(strings.TrimSpace(string(key)))
+}
+
+// HashLongKeys returns a KeyTransformer replacing keys longer than maxLen with their hex encoded
+// SHA-256 digest, maxLen must be at least 64, the length of a digest, to stay idempotent
+func HashLongKeys(maxLen int) KeyTransformer {
+	if maxLen < sha256.Size*2 {
+		maxLen = sha256.Size * 2
+	}
+	return func(key CacheKey) CacheKey {
+		if len(key) <= maxLen {
+			return key
+		}
+		digest := sha256.Sum256([]byte(key))
+		return CacheKey(hex.EncodeToString(digest[:]))
+	}
+}
+
+// ChainKeyTransformers applies the transformers in order
+func ChainKeyTransformers(transformers ...KeyTransformer) KeyTransformer {
+	return func(key CacheKey) CacheKey {
+		for _, transformer := range transformers {
+			key = transformer(key)
+		}
+		return key
+	}
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestKeyTransformer(t *testing.T) {
+	cache := NewCache(5, LRU)
+	cache.SetKeyTransformer(ChainKeyTransformers(TrimKeys, LowercaseKeys, HashLongKeys(64)))
+
+	cache.Put(" User:1 ", "1")
+	test(t, cache, [][]interface{}{
+		{"Get", "user:1", "1"},
+		{"Get", "USER:1", "1"},
+	})
+	if cache.size != 1 {
+		t.Errorf("size should be 1, but got %d", cache.size)
+	}
+
+	long := CacheKey(strings.Repeat("k", 100))
+	cache.Put(long, "long")
+	for key := range cache.data {
+		if len(key) > 64 {
+			t.Errorf("long keys should be hashed, but got %s", key)
+		}
+	}
+	if value, err := cache.Get(CacheKey(strings.ToUpper(string(long)))); err != nil || *value != "long" {
+		t.Errorf("long key should be found through its digest")
+	}
+
+	cache.Delete(" USER:1")
+	if _, _, err := cache.Peek("user:1"); err == nil {
+		t.Errorf("Delete should canonicalize the key")
+	}
+}
+
+func TestKeyTransformerEntryPoints(t *testing.T) {
+	cache := NewCache(5, LRU)
+	cache.SetKeyTransformer(LowercaseKeys)
+	cache.EnableOrderedIndex()
+	cache.Put("a", "a")
+	cache.Put("b", "b")
+
+	if value, err := cache.View().Get("A"); err != nil || *value != "a" {
+		t.Errorf("View.Get should canonicalize the key")
+	}
+	if keys, _ := cache.KeysAfter("A", 5); len(keys) != 1 || keys[0] != "b" {
+		t.Errorf("KeysAfter should canonicalize the key, but got %v", keys)
+	}
+
+	var buf bytes.Buffer
+	source := NewCache(5, LRU)
+	source.Put("C", "c")
+	source.ExportNDJSON(&buf)
+	cache.ImportNDJSON(&buf)
+	if _, _, err := cache.Peek("c"); err != nil || cache.size != 3 {
+		t.Errorf("imported keys should be canonicalized")
+	}
+}
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+// KeyedMutex serializes critical sections per key using lock striping, keys hashing to the
+// same stripe share a mutex so memory stays bounded regardless of the number of keys
+type KeyedMutex struct {
+	stripes []sync.Mutex
+}
+
+func NewKeyedMutex(stripes int) *KeyedMutex {
+	if stripes < 1 {
+		stripes = 1
+	}
+	mutex := &KeyedMutex{}
+	mutex.stripes = make([]sync.Mutex, stripes)
+	return mutex
+}
+
+func (m *KeyedMutex) Lock(key CacheKey) {
+	m.stripe(key).Lock()
+}
+
+func (m *KeyedMutex) Unlock(key CacheKey) {
+	m.stripe(key).Unlock()
+}
+
+func (m *KeyedMutex) stripe(key CacheKey) *sync.Mutex {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return &m.stripes[hash.Sum32()%uint32(len(m.stripes))]
+}
@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestKeyedMutex(t *testing.T) {
+	mutex := NewKeyedMutex(4)
+	counters := map[CacheKey]*int{"a": new(int), "b": new(int)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		for key, counter := range counters {
+			wg.Add(1)
+			go func(key CacheKey, counter *int) {
+				defer wg.Done()
+				mutex.Lock(key)
+				*counter++
+				mutex.Unlock(key)
+			}(key, counter)
+		}
+	}
+	wg.Wait()
+
+	for key, counter := range counters {
+		if *counter != 100 {
+			t.Errorf("key = %s, counter should be 100, but got %d", key, *counter)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package cache
+
+import "container/list"
+
+// LIRS, low inter-reference recency set: keys are ranked by the recency of their previous
+// access rather than their last one. Keys with a short reuse distance are LIR and are never
+// evicted, the remaining resident keys are HIR and are evicted in FIFO order. The stack holds
+// LIR keys, resident HIR keys and the ghosts of evicted HIR keys by recency, a HIR key accessed
+// again while still on the stack has a shorter reuse distance than the oldest LIR key and takes
+// its place. The stack is pruned so that its bottom is always a LIR key
+type LIRSPolicy struct {
+	stack    *list.List // most recent at the front
+	hirQueue *list.List // resident HIR keys, next victim at the back
+	ghosts   *list.List // non-resident HIR keys still on the stack, oldest at the back
+	entries  map[CacheKey]*lirsEntry
+	lirCount int
+	hirRatio float64
+}
+
+type lirsState int
+
+const (
+	lirsLIR lirsState = iota
+	lirsHIR
+	lirsGhost
+)
+
+type lirsEntry struct {
+	key   CacheKey
+	state lirsState
+	stack *list.Element // nil when the key is not on the stack
+	queue *list.Element // element in hirQueue or ghosts
+}
+
+// LIRSOption configures a LIRSPolicy
+type LIRSOption func(*LIRSPolicy)
+
+// WithHIRRatio sets the share of the resident keys kept as HIR, 0.01 by default. At least one
+// resident key is always HIR once the cache holds two keys
+func WithHIRRatio(ratio float64) LIRSOption {
+	return func(p *LIRSPolicy) {
+		p.hirRatio = ratio
+	}
+}
+
+func NewLIRSPolicy(opts ...LIRSOption) CachePolicy {
+	policy := &LIRSPolicy{}
+	policy.stack = list.New()
+	policy.hirQueue = list.New()
+	policy.ghosts = list.New()
+	policy.entries = make(map[CacheKey]*lirsEntry)
+	policy.hirRatio = 0.01
+	for _, opt := range opts {
+		opt(policy)
+	}
+	return policy
+}
+
+func (p *LIRSPolicy) Victim() CacheKey {
+	if p.hirQueue.Len() == 0 {
+		p.demote()
+	}
+	key := p.hirQueue.Back().Value.(*lirsEntry).key
+	p.Evict(key)
+	return key
+}
+
+// Evict evicts a resident HIR key, leaving a ghost when it is still on the stack. A LIR key
+// evicted ahead of the HIR keys leaves no ghost
+func (p *LIRSPolicy) Evict(key CacheKey) {
+	entry, ok := p.entries[key]
+	if !ok || entry.state == lirsGhost {
+		return
+	}
+	if entry.state == lirsLIR {
+		p.Remove(key)
+		return
+	}
+	p.hirQueue.Remove(entry.queue)
+	if entry.stack == nil {
+		delete(p.entries, entry.key)
+		return
+	}
+	entry.state = lirsGhost
+	entry.queue = p.ghosts.PushFront(entry)
+	p.trimGhosts()
+}
+
+func (p *LIRSPolicy) Add(key CacheKey) {
+	if entry, ok := p.entries[key]; ok && entry.state == lirsGhost {
+		// reused before its ghost left the stack, its reuse distance beats the oldest LIR key
+		p.ghosts.Remove(entry.queue)
+		entry.queue = nil
+		entry.state = lirsLIR
+		p.lirCount++
+		p.stack.MoveToFront(entry.stack)
+		if p.lirCount > p.lirLimit(p.residents()) {
+			p.demote()
+		}
+		return
+	}
+	entry := &lirsEntry{key: key}
+	p.entries[key] = entry
+	entry.stack = p.stack.PushFront(entry)
+	if p.lirCount < p.lirLimit(p.residents()+1) {
+		entry.state = lirsLIR
+		p.lirCount++
+		return
+	}
+	entry.state = lirsHIR
+	entry.queue = p.hirQueue.PushFront(entry)
+}
+
+func (p *LIRSPolicy) Remove(key CacheKey) {
+	entry, ok := p.entries[key]
+	if !ok || entry.state == lirsGhost {
+		return
+	}
+	delete(p.entries, key)
+	if entry.queue != nil {
+		p.hirQueue.Remove(entry.queue)
+	}
+	if entry.stack != nil {
+		p.stack.Remove(entry.stack)
+	}
+	if entry.state == lirsLIR {
+		p.lirCount--
+		p.prune()
+	}
+}
+
+func (p *LIRSPolicy) Access(key CacheKey) {
+	entry, ok := p.entries[key]
+	if !ok || entry.state == lirsGhost {
+		return
+	}
+	switch {
+	case entry.state == lirsLIR:
+		p.stack.MoveToFront(entry.stack)
+		p.prune()
+	case entry.stack != nil:
+		// a HIR key reused while on the stack becomes LIR
+		p.hirQueue.Remove(entry.queue)
+		entry.queue = nil
+		entry.state = lirsLIR
+		p.lirCount++
+		p.stack.MoveToFront(entry.stack)
+		if p.lirCount > p.lirLimit(p.residents()) {
+			p.demote()
+		}
+	default:
+		entry.stack = p.stack.PushFront(entry)
+		p.hirQueue.MoveToFront(entry.queue)
+	}
+}
+
+// demote turns the LIR key at the bottom of the stack into a resident HIR key, making room for
+// a key that just became LIR
+func (p *LIRSPolicy) demote() {
+	bottom := p.stack.Back()
+	if bottom == nil {
+		return
+	}
+	entry := bottom.Value.(*lirsEntry)
+	p.stack.Remove(bottom)
+	entry.stack = nil
+	entry.state = lirsHIR
+	entry.queue = p.hirQueue.PushFront(entry)
+	p.lirCount--
+	p.prune()
+}
+
+// prune pops HIR keys and ghosts off the bottom of the stack until a LIR key is at the bottom,
+// ghosts leaving the stack are forgotten
+func (p *LIRSPolicy) prune() {
+	for bottom := p.stack.Back(); bottom != nil; bottom = p.stack.Back() {
+		entry := bottom.Value.(*lirsEntry)
+		if entry.state == lirsLIR {
+			return
+		}
+		p.stack.Remove(bottom)
+		entry.stack = nil
+		if entry.state == lirsGhost {
+			p.ghosts.Remove(entry.queue)
+			delete(p.entries, entry.key)
+		}
+	}
+}
+
+// trimGhosts forgets the oldest ghosts once there are more ghosts than resident keys, bounding
+// the stack to twice the resident keys
+func (p *LIRSPolicy) trimGhosts() {
+	for p.ghosts.Len() > p.residents() {
+		entry := p.ghosts.Remove(p.ghosts.Back()).(*lirsEntry)
+		p.stack.Remove(entry.stack)
+		delete(p.entries, entry.key)
+	}
+}
+
+func (p *LIRSPolicy) residents() int {
+	return p.lirCount + p.hirQueue.Len()
+}
+
+func (p *LIRSPolicy) lirLimit(residents int) int {
+	limit := int((1 - p.hirRatio) * float64(residents))
+	if limit >= residents {
+		limit = residents - 1
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	return limit
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLIRSPolicy(t *testing.T) {
+	testCase := [][]interface{}{
+		{"Put", "1", "1"}, // LIR
+		{"Put", "2", "2"}, // HIR
+		{"Put", "3", "3"}, // LIR
+		{"Get", "2", "2"}, // 2 becomes LIR, 1 is demoted to HIR
+		{"Put", "4", "4"}, // 1 is evicted
+		{"Get", "1", nil},
+		{"Put", "5", "5"}, // 4 is evicted and leaves a ghost
+		{"Put", "4", "4"}, // 5 is evicted, 4 is reused as a ghost and becomes LIR, 3 is demoted
+		{"Put", "6", "6"}, // 3 is evicted
+		{"Put", "7", "7"}, // 6 is evicted, the scan can't reach the LIR keys
+		{"Get", "2", "2"},
+		{"Get", "4", "4"},
+		{"Get", "3", nil},
+		{"Get", "6", nil},
+		{"Get", "7", "7"},
+	}
+
+	cache := NewCache(3, LIRS)
+	test(t, cache, testCase)
+}
+
+func TestLIRSPolicyBoundsGhosts(t *testing.T) {
+	policy := NewLIRSPolicy(WithHIRRatio(0.5)).(*LIRSPolicy)
+	cache := NewCacheWithPolicy(10, policy)
+	for i := 0; i < 1000; i++ {
+		cache.Put(CacheKey(fmt.Sprint(i%50)), "v")
+		cache.Get(CacheKey(fmt.Sprint(i % 7)))
+	}
+	if len(policy.entries) > 2*cache.size {
+		t.Errorf("policy tracks %d keys, but at most %d are expected", len(policy.entries), 2*cache.size)
+	}
+	if policy.residents() != cache.size {
+		t.Errorf("policy has %d resident keys, but the cache has %d", policy.residents(), cache.size)
+	}
+	for key := range cache.data {
+		cache.Delete(key)
+	}
+	if len(policy.entries) != 0 || policy.stack.Len() != 0 {
+		t.Errorf("policy should be empty, but tracks %d keys", len(policy.entries))
+	}
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Logger receives the diagnostics of the cache components, keyValues alternate keys and values
+type Logger interface {
+	Debug(msg string, keyValues ...interface{})
+	Info(msg string, keyValues ...interface{})
+	Warn(msg string, keyValues ...interface{})
+	Error(msg string, keyValues ...interface{})
+}
+
+// nopLogger discards everything, it is the default Logger
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+// StdLogger adapts a standard library *log.Logger, lines look like "WARN msg key=value"
+type StdLogger struct {
+	logger *log.Logger
+}
+
+func NewStdLogger(logger *log.Logger) *StdLogger {
+	return &StdLogger{logger}
+}
+
+func (l *StdLogger) Debug(msg string, keyValues ...interface{}) {
+	l.print("DEBUG", msg, keyValues)
+}
+
+func (l *StdLogger) Info(msg string, keyValues ...interface{}) {
+	l.print("INFO", msg, keyValues)
+}
+
+func (l *StdLogger) Warn(msg string, keyValues ...interface{}) {
+	l.print("WARN", msg, keyValues)
+}
+
+func (l *StdLogger) Error(msg string, keyValues ...interface{}) {
+	l.print("ERROR", msg, keyValues)
+}
+
+func (l *StdLogger) print(level string, msg string, keyValues []interface{}) {
+	var line strings.Builder
+	line.WriteString(level)
+	line.WriteString(" ")
+	line.WriteString(msg)
+	for i := 0; i < len(keyValues); i += 2 {
+		if i+1 < len(keyValues) {
+			fmt.Fprintf(&line, " %v=%v", keyValues[i], keyValues[i+1])
+		} else {
+			fmt.Fprintf(&line, " %v", keyValues[i])
+		}
+	}
+	l.logger.Print(line.String())
+}
+
+func (c *Cache) SetLogger(logger Logger) {
+	if logger == nil {
+		logger = nopLogger{}
+	}
+	c.logger = logger
+}
@@ -0,0 +1,22 @@
+package cache
+
+import (
+	"bytes"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	cache := NewCache(2, LRU)
+	cache.SetLogger(NewStdLogger(log.New(&buf, "", 0)))
+	cache.SetTombstoneGrace(time.Hour)
+
+	cache.Put("1", "1")
+	cache.Delete("1")
+	cache.Put("1", "late")
+	if buf.String() != "WARN late write on deleted key key=1\n" {
+		t.Errorf("late write should be logged, but got %q", buf.String())
+	}
+}
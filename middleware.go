@@ -0,0 +1,293 @@
+package cache
+
+import "container/list"
+
+// PolicyMiddleware wraps a policy to add a cross-cutting behavior, such as pinning, tracing or
+// scan bypass, on top of any base policy
+type PolicyMiddleware func(CachePolicy) CachePolicy
+
+// WrapPolicy applies the middlewares to policy, the first middleware is the outermost layer
+func WrapPolicy(policy CachePolicy, middlewares ...PolicyMiddleware) CachePolicy {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		policy = middlewares[i](policy)
+	}
+	return policy
+}
+
+// policyLayer is implemented by the middlewares, unwrap returns the policy the layer wraps
+type policyLayer interface {
+	unwrap() CachePolicy
+}
+
+// basePolicy strips the middleware layers off policy, the cache looks for optional interfaces
+// such as FrequencyPolicy on the policy they wrap
+func basePolicy(policy CachePolicy) CachePolicy {
+	for {
+		layer, ok := policy.(policyLayer)
+		if !ok {
+			return policy
+		}
+		policy = layer.unwrap()
+	}
+}
+
+// PolicyEvent is a call made on a policy, as reported by WithTracing
+type PolicyEvent struct {
+	Op  string // "Add", "Remove", "Access", "Victim" or "Evict"
+	Key CacheKey
+}
+
+// WithTracing reports every policy call to trace
+func WithTracing(trace func(PolicyEvent)) PolicyMiddleware {
+	return func(next CachePolicy) CachePolicy {
+		return &tracingPolicy{next, trace}
+	}
+}
+
+type tracingPolicy struct {
+	next  CachePolicy
+	trace func(PolicyEvent)
+}
+
+func (p *tracingPolicy) Victim() CacheKey {
+	key := p.next.Victim()
+	p.trace(PolicyEvent{"Victim", key})
+	return key
+}
+
+func (p *tracingPolicy) Add(key CacheKey) {
+	p.trace(PolicyEvent{"Add", key})
+	p.next.Add(key)
+}
+
+func (p *tracingPolicy) Remove(key CacheKey) {
+	p.trace(PolicyEvent{"Remove", key})
+	p.next.Remove(key)
+}
+
+func (p *tracingPolicy) Access(key CacheKey) {
+	p.trace(PolicyEvent{"Access", key})
+	p.next.Access(key)
+}
+
+func (p *tracingPolicy) supportsCandidates() bool {
+	_, ok := candidatePolicy(p.next)
+	return ok
+}
+
+func (p *tracingPolicy) Candidates(fn func(CacheKey) bool) {
+	if next, ok := candidatePolicy(p.next); ok {
+		next.Candidates(fn)
+	}
+}
+
+func (p *tracingPolicy) Evict(key CacheKey) {
+	p.trace(PolicyEvent{"Evict", key})
+	p.next.(CandidatePolicy).Evict(key)
+}
+
+func (p *tracingPolicy) unwrap() CachePolicy {
+	return p.next
+}
+
+// Pinner is the policy returned by WithPinning
+// Pin takes a resident key out of the eviction candidates until it is unpinned, other keys are
+// ignored
+// Unpin makes a pinned key eligible for eviction again
+type Pinner interface {
+	CachePolicy
+	Pin(CacheKey)
+	Unpin(CacheKey)
+}
+
+// WithPinning lets keys be pinned so they are never elected as victims. Pinned keys stay in the
+// wrapped policy, which keeps tracking their accesses, and are skipped when it offers candidates.
+// A wrapped policy that doesn't implement CandidatePolicy loses the state of pinned keys, they
+// are removed from it and added back when unpinned. When every resident key is pinned there is
+// no victim and new keys are not stored
+func WithPinning() PolicyMiddleware {
+	return func(next CachePolicy) CachePolicy {
+		return &pinningPolicy{next, make(map[CacheKey]bool), make(map[CacheKey]bool)}
+	}
+}
+
+type pinningPolicy struct {
+	next     CachePolicy
+	pinned   map[CacheKey]bool
+	resident map[CacheKey]bool
+}
+
+func (p *pinningPolicy) Victim() CacheKey {
+	if len(p.pinned) == len(p.resident) {
+		return "" // every key is pinned, the cache checks victims are resident
+	}
+	next, ok := candidatePolicy(p.next)
+	if !ok {
+		key := p.next.Victim()
+		delete(p.resident, key)
+		return key
+	}
+	var victim CacheKey
+	p.Candidates(func(key CacheKey) bool {
+		victim = key
+		return false
+	})
+	next.Evict(victim)
+	delete(p.resident, victim)
+	return victim
+}
+
+func (p *pinningPolicy) Add(key CacheKey) {
+	p.resident[key] = true
+	p.next.Add(key)
+}
+
+func (p *pinningPolicy) Remove(key CacheKey) {
+	if !p.resident[key] {
+		return
+	}
+	delete(p.resident, key)
+	if p.pinned[key] {
+		delete(p.pinned, key)
+		if _, ok := candidatePolicy(p.next); !ok {
+			return // already out of the wrapped policy
+		}
+	}
+	p.next.Remove(key)
+}
+
+func (p *pinningPolicy) Access(key CacheKey) {
+	if _, ok := candidatePolicy(p.next); ok || !p.pinned[key] {
+		p.next.Access(key)
+	}
+}
+
+func (p *pinningPolicy) supportsCandidates() bool {
+	_, ok := candidatePolicy(p.next)
+	return ok
+}
+
+func (p *pinningPolicy) Candidates(fn func(CacheKey) bool) {
+	next, ok := candidatePolicy(p.next)
+	if !ok {
+		return
+	}
+	next.Candidates(func(key CacheKey) bool {
+		return p.pinned[key] || fn(key)
+	})
+}
+
+func (p *pinningPolicy) Evict(key CacheKey) {
+	delete(p.resident, key)
+	p.next.(CandidatePolicy).Evict(key)
+}
+
+func (p *pinningPolicy) unwrap() CachePolicy {
+	return p.next
+}
+
+func (p *pinningPolicy) Pin(key CacheKey) {
+	if !p.resident[key] || p.pinned[key] {
+		return
+	}
+	p.pinned[key] = true
+	if _, ok := candidatePolicy(p.next); !ok {
+		p.next.Remove(key)
+	}
+}
+
+func (p *pinningPolicy) Unpin(key CacheKey) {
+	if !p.pinned[key] {
+		return
+	}
+	delete(p.pinned, key)
+	if _, ok := candidatePolicy(p.next); !ok {
+		p.next.Add(key)
+	}
+}
+
+// WithScanBypass keeps scans from flushing the working set. Once length keys were added in a row
+// without any access in between, the next keys added are held outside the wrapped policy. They are
+// the first victims, oldest first, and join the wrapped policy on their first access
+func WithScanBypass(length int) PolicyMiddleware {
+	return func(next CachePolicy) CachePolicy {
+		policy := &scanBypassPolicy{next: next, length: length}
+		policy.scanned = list.New()
+		policy.keyNode = make(map[CacheKey]*list.Element)
+		return policy
+	}
+}
+
+type scanBypassPolicy struct {
+	next    CachePolicy
+	length  int
+	run     int // keys added since the last access
+	scanned *list.List
+	keyNode map[CacheKey]*list.Element
+}
+
+func (p *scanBypassPolicy) Victim() CacheKey {
+	if p.scanned.Len() > 0 {
+		key := p.scanned.Front().Value.(CacheKey)
+		p.Remove(key)
+		return key
+	}
+	return p.next.Victim()
+}
+
+func (p *scanBypassPolicy) Add(key CacheKey) {
+	p.run++
+	if p.run <= p.length {
+		p.next.Add(key)
+		return
+	}
+	p.keyNode[key] = p.scanned.PushBack(key)
+}
+
+func (p *scanBypassPolicy) Remove(key CacheKey) {
+	if node, ok := p.keyNode[key]; ok {
+		p.scanned.Remove(node)
+		delete(p.keyNode, key)
+		return
+	}
+	p.next.Remove(key)
+}
+
+func (p *scanBypassPolicy) Access(key CacheKey) {
+	p.run = 0
+	if node, ok := p.keyNode[key]; ok {
+		p.scanned.Remove(node)
+		delete(p.keyNode, key)
+		p.next.Add(key)
+		return
+	}
+	p.next.Access(key)
+}
+
+func (p *scanBypassPolicy) supportsCandidates() bool {
+	_, ok := candidatePolicy(p.next)
+	return ok
+}
+
+func (p *scanBypassPolicy) Candidates(fn func(CacheKey) bool) {
+	for node := p.scanned.Front(); node != nil; node = node.Next() {
+		if !fn(node.Value.(CacheKey)) {
+			return
+		}
+	}
+	if next, ok := candidatePolicy(p.next); ok {
+		next.Candidates(fn)
+	}
+}
+
+func (p *scanBypassPolicy) Evict(key CacheKey) {
+	if _, ok := p.keyNode[key]; ok {
+		p.Remove(key)
+		return
+	}
+	p.next.(CandidatePolicy).Evict(key)
+}
+
+func (p *scanBypassPolicy) unwrap() CachePolicy {
+	return p.next
+}
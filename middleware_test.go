@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestPolicyMiddleware(t *testing.T) {
+	events := []PolicyEvent{}
+	policy := WrapPolicy(NewLRUPolicy(),
+		WithPinning(),
+		WithTracing(func(event PolicyEvent) { events = append(events, event) }),
+	)
+	cache := NewCacheWithPolicy(2, policy)
+
+	cache.Put("1", "1")
+	policy.(Pinner).Pin("1")
+	cache.Put("2", "2")
+	cache.Put("3", "3") // 1 is pinned, 2 is evicted
+	test(t, cache, [][]interface{}{
+		{"Get", "1", "1"},
+		{"Get", "2", nil},
+		{"Get", "3", "3"},
+	})
+
+	expected := []PolicyEvent{
+		{"Add", "1"},
+		{"Add", "2"},
+		{"Evict", "2"}, // 1 is pinned, 2 is the first other candidate
+		{"Add", "3"},
+		{"Access", "1"}, // pinned keys keep their policy state
+		{"Access", "3"},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("events should be %v, but got %v", expected, events)
+	}
+}
+
+func TestPinningKeepsPolicyState(t *testing.T) {
+	lfu := NewLFUPolicy().(*LFUPolicy)
+	policy := WrapPolicy(lfu, WithPinning())
+	pinner := policy.(Pinner)
+	cache := NewCacheWithPolicy(2, policy)
+	pinner.Pin("missing") // not resident, ignored
+
+	cache.Put("1", "1")
+	cache.Get("1")
+	cache.Put("2", "2")
+	pinner.Pin("1")
+	pinner.Pin("2")
+	cache.Put("3", "3") // every key is pinned, 3 is not stored
+	test(t, cache, [][]interface{}{
+		{"Get", "3", nil},
+		{"Get", "1", "1"},
+		{"Get", "2", "2"},
+	})
+	cache.Resize(1)
+	if cache.size != 2 {
+		t.Errorf("pinned keys should not be evicted, but size is %d", cache.size)
+	}
+	cache.Resize(2)
+
+	pinner.Unpin("1")
+	pinner.Unpin("2")
+	if count, _ := lfu.Frequency("1"); count != 3 {
+		t.Errorf("access count of key = 1 should be 3, but got %d", count)
+	}
+	cache.Put("3", "3") // 2 is the least frequently used
+	test(t, cache, [][]interface{}{
+		{"Get", "2", nil},
+		{"Get", "1", "1"},
+	})
+}
+
+// victimOnlyPolicy hides the Candidates of the policy it embeds
+type victimOnlyPolicy struct {
+	CachePolicy
+}
+
+func TestPinningWithoutCandidates(t *testing.T) {
+	policy := WrapPolicy(victimOnlyPolicy{NewLRUPolicy()}, WithPinning())
+	cache := NewCacheWithPolicy(2, policy)
+	cache.SetStickyWindow(0, 100)
+
+	cache.Put("1", "1")
+	cache.Put("2", "2")
+	policy.(Pinner).Pin("1")
+	cache.Put("3", "3") // 2 is sticky but the only unpinned key, it is evicted anyway
+	test(t, cache, [][]interface{}{
+		{"Get", "1", "1"},
+		{"Get", "2", nil},
+		{"Get", "3", "3"},
+	})
+}
+
+func TestScanBypass(t *testing.T) {
+	cache := NewCacheWithPolicy(6, WrapPolicy(NewLRUPolicy(), WithScanBypass(2)))
+	for _, key := range []CacheKey{"1", "2", "3"} {
+		cache.Put(key, string(key))
+		cache.Get(key)
+	}
+	for i := 0; i < 10; i++ {
+		key := CacheKey(fmt.Sprint("scan", i))
+		cache.Put(key, string(key)) // scan0 and scan1 join the policy, the rest evict each other
+	}
+	test(t, cache, [][]interface{}{
+		{"Get", "1", "1"},
+		{"Get", "2", "2"},
+		{"Get", "3", "3"},
+		{"Get", "scan0", "scan0"},
+		{"Get", "scan8", nil},
+		{"Get", "scan9", "scan9"}, // accessed, it joins the policy
+	})
+	cache.Put("4", "4") // no scan in progress, the least recently used key is evicted
+	test(t, cache, [][]interface{}{
+		{"Get", "scan1", nil},
+		{"Get", "scan9", "scan9"},
+	})
+}
+
+func TestMiddlewareKeepsFrequencies(t *testing.T) {
+	policy := WrapPolicy(NewLFUPolicy(), WithPinning(), WithTracing(func(PolicyEvent) {}))
+	cache := NewCacheWithPolicy(2, policy)
+	cache.Put("1", "1")
+	cache.Get("1")
+	if count, err := cache.AccessCount("1"); err != nil || count != 2 {
+		t.Errorf("access count of key = 1 should be 2 through the middlewares, but got %d, %v", count, err)
+	}
+	if histogram, err := cache.FrequencyHistogram(); err != nil || histogram[2] != 1 {
+		t.Errorf("histogram should count key = 1 at frequency 2, but got %v, %v", histogram, err)
+	}
+
+	var buf bytes.Buffer
+	cache.TransferTo(&buf)
+	received := NewCache(2, LFU)
+	received.ReceiveFrom(&buf)
+	if count, _ := received.AccessCount("1"); count != 2 {
+		t.Errorf("transferred access count of key = 1 should be 2, but got %d", count)
+	}
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"math"
+	"sort"
+	"sync/atomic"
+)
+
+// MissCosts totals the estimated cost of the requests seen so far, Saved by hits and Incurred by
+// misses, in the unit given to SetMissCost, e.g. milliseconds or dollars
+type MissCosts struct {
+	Saved    float64
+	Incurred float64
+}
+
+// missCostTable maps key prefixes to the estimated cost of a miss
+type missCostTable struct {
+	costs   map[string]float64
+	lengths []int // distinct lengths of the prefixes, longest first
+}
+
+// cost returns the cost of the longest prefix matching key, keys matching no prefix cost nothing.
+// It looks the key up once per distinct prefix length rather than once per prefix
+func (t *missCostTable) cost(key CacheKey) float64 {
+	for _, length := range t.lengths {
+		if length > len(key) {
+			continue
+		}
+		if cost, ok := t.costs[string(key[:length])]; ok {
+			return cost
+		}
+	}
+	return 0
+}
+
+func (t *missCostTable) updateLengths() {
+	seen := make(map[int]bool)
+	t.lengths = t.lengths[:0]
+	for prefix := range t.costs {
+		if !seen[len(prefix)] {
+			seen[len(prefix)] = true
+			t.lengths = append(t.lengths, len(prefix))
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(t.lengths)))
+}
+
+// SetMissCost sets the estimated cost of a miss on keys starting with prefix, the longest
+// matching prefix wins. A zero cost removes the prefix
+func (c *Cache) SetMissCost(prefix string, cost float64) {
+	if c.missCosts == nil {
+		c.missCosts = &missCostTable{costs: make(map[string]float64)}
+	}
+	if cost == 0 {
+		delete(c.missCosts.costs, prefix)
+	} else {
+		c.missCosts.costs[prefix] = cost
+	}
+	c.missCosts.updateLengths()
+}
+
+// MissCosts returns the estimated cost saved by hits and incurred by misses since the first
+// SetMissCost call, they are also reported by Stats
+func (c *Cache) MissCosts() MissCosts {
+	return MissCosts{
+		Saved:    loadFloat(&c.stats.missCostSaved),
+		Incurred: loadFloat(&c.stats.missCostIncurred),
+	}
+}
+
+func (c *Cache) recordMissCost(key CacheKey, hit bool) {
+	if c.missCosts == nil {
+		return
+	}
+	if hit {
+		addFloat(&c.stats.missCostSaved, c.missCosts.cost(key))
+	} else {
+		addFloat(&c.stats.missCostIncurred, c.missCosts.cost(key))
+	}
+}
+
+// addFloat atomically adds delta to the float64 stored as bits in addr
+func addFloat(addr *uint64, delta float64) {
+	if delta == 0 {
+		return
+	}
+	for {
+		old := atomic.LoadUint64(addr)
+		if atomic.CompareAndSwapUint64(addr, old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+func loadFloat(addr *uint64) float64 {
+	return math.Float64frombits(atomic.LoadUint64(addr))
+}
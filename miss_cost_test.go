@@ -0,0 +1,42 @@
+package cache
+
+import "testing"
+
+func TestMissCosts(t *testing.T) {
+	cache := NewCache(2, LRU)
+	cache.SetMissCost("user:", 10)
+	cache.SetMissCost("user:admin:", 50)
+	test(t, cache, [][]interface{}{
+		{"Get", "user:1", nil},
+		{"Put", "user:1", "1"},
+		{"Get", "user:1", "1"},
+		{"Get", "user:1", "1"},
+		{"Get", "user:admin:1", nil},
+		{"Get", "order:1", nil},
+	})
+	want := MissCosts{Saved: 20, Incurred: 60}
+	if got := cache.MissCosts(); got != want {
+		t.Errorf("MissCosts = %+v, but want %+v", got, want)
+	}
+
+	if stats := cache.Stats(); stats.MissCostSaved != 20 || stats.MissCostIncurred != 60 {
+		t.Errorf("stats should report 20 saved and 60 incurred, but got %v and %v", stats.MissCostSaved, stats.MissCostIncurred)
+	}
+
+	cache.SetMissCost("user:admin:", 0)
+	cache.Get("user:admin:1")
+	if got := cache.MissCosts().Incurred; got != 70 {
+		t.Errorf("incurred = %v, but want 70", got)
+	}
+
+	sharded, _ := NewShardedCache(4, 2, LRU, nil)
+	for _, s := range sharded.shards {
+		s.cache.SetMissCost("", 1)
+	}
+	for _, key := range []CacheKey{"1", "2", "3"} {
+		sharded.Get(key)
+	}
+	if got := sharded.Stats().MissCostIncurred; got != 3 {
+		t.Errorf("sharded incurred = %v, but want 3", got)
+	}
+}
@@ -0,0 +1,29 @@
+package cache
+
+import "errors"
+
+// Move transfers key with its value and metadata from c to dst. The entry is stored in dst
+// before it leaves c so that a reader checking dst then c never misses it, the caller must hold
+// the locks of both caches. The entry stays in c when dst doesn't admit it or dst is c
+func (c *Cache) Move(dst *Cache, key CacheKey) error {
+	if dst == c {
+		return errors.New("cannot move to the same cache")
+	}
+	key = c.canonical(key)
+	value, ok := c.data[key]
+	if !ok || c.tombstoned(key) || c.outdated(key) {
+		return errors.New("key not found")
+	}
+	meta := c.meta[key]
+	dstKey := dst.canonical(key)
+	dst.put(dstKey, value, meta.source, 0)
+	moved, ok := dst.meta[dstKey]
+	if !ok || dst.data[dstKey] != value {
+		return errors.New("key not admitted")
+	}
+	moved.insertedAt = meta.insertedAt
+	moved.writtenAt = meta.writtenAt
+	moved.accessed = meta.accessed
+	c.remove(key)
+	return nil
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMove(t *testing.T) {
+	src := NewCache(2, LRU)
+	dst := NewCache(2, LRU)
+	inserted := time.Unix(1000, 0)
+	src.now = func() time.Time { return inserted }
+	src.PutWithSource("1", "1", "loader")
+	src.Get("1")
+
+	if err := src.Move(dst, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Move(dst, "1"); err == nil {
+		t.Errorf("moving a missing key should fail")
+	}
+	test(t, src, [][]interface{}{
+		{"Get", "1", nil},
+	})
+	meta := dst.meta["1"]
+	if !meta.insertedAt.Equal(inserted) || !meta.accessed || meta.source != "loader" {
+		t.Errorf("metadata should move with the entry, but got %+v", meta)
+	}
+	test(t, dst, [][]interface{}{
+		{"Get", "1", "1"},
+	})
+
+	dst.SetWarmup(time.Hour, 0)
+	src.Put("2", "2")
+	if err := src.Move(dst, "2"); err == nil {
+		t.Errorf("Move should fail when dst doesn't admit the key")
+	}
+	test(t, src, [][]interface{}{
+		{"Get", "2", "2"},
+	})
+}
+
+func TestMoveToSelf(t *testing.T) {
+	cache := NewCache(2, LRU)
+	cache.Put("1", "1")
+	if err := cache.Move(cache, "1"); err == nil {
+		t.Errorf("moving to the same cache should fail")
+	}
+	if value, _ := cache.Get("1"); value == nil || *value != "1" {
+		t.Errorf("key = 1 should stay in the cache")
+	}
+}
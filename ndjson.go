@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ndjsonEntry is one line of the NDJSON export, entries of TransferTo streams are migrated in the
+// same shape
+type ndjsonEntry struct {
+	Key         CacheKey `json:"key"`
+	Value       string   `json:"value"`
+	AccessCount int      `json:"access_count,omitempty"`
+}
+
+// ndjsonHeader is the first line of the NDJSON export
+type ndjsonHeader struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+}
+
+// ExportNDJSON writes the resident entries to w as newline delimited JSON, one entry per line
+// after a format version header line, so the content can be piped through standard tooling
+func (c *Cache) ExportNDJSON(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	encoder := json.NewEncoder(writer)
+	if err := encoder.Encode(ndjsonHeader{ndjsonFormat, formatVersion}); err != nil {
+		return err
+	}
+	for key, value := range c.Snapshot() {
+		if _, ok := c.tombstones[key]; ok {
+			continue
+		}
+		count, _ := c.AccessCount(key)
+		if err := encoder.Encode(ndjsonEntry{key, value, count}); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// ImportNDJSON reads entries written by ExportNDJSON and puts them in the cache, exports of older
+// releases are migrated and a *FormatVersionError is returned when that isn't possible. Errors
+// tell the line they were found on, entries without a key are rejected. It returns the number of
+// entries imported
+func (c *Cache) ImportNDJSON(r io.Reader) (int, error) {
+	decoder := json.NewDecoder(r)
+	imported, lines := 0, 0
+	version := -1 // until the first line tells whether there is a header
+	for {
+		lines++
+		var line struct {
+			ndjsonHeader
+			ndjsonEntry
+		}
+		err := decoder.Decode(&line)
+		if err == io.EOF {
+			c.logger.Info("ndjson import done", "entries", imported)
+			return imported, nil
+		}
+		if err == nil && version < 0 {
+			version = 0
+			if line.Format == ndjsonFormat {
+				version = line.Version
+			}
+			err = checkFormatVersion(version)
+			if line.Format == ndjsonFormat && err == nil {
+				continue
+			}
+		}
+		if err == nil {
+			err = migrate(&line.ndjsonEntry, version)
+		}
+		if err == nil && line.Key == "" {
+			err = errors.New("empty key")
+		}
+		if err != nil {
+			err = fmt.Errorf("line %d: %w", lines, err)
+			c.logger.Error("ndjson import failed", "entries", imported, "error", err)
+			return imported, err
+		}
+		c.restore(line.Key, line.Value, line.AccessCount)
+		imported++
+	}
+}
@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNDJSON(t *testing.T) {
+	source := NewCache(3, LFU)
+	source.Put("1", "1")
+	source.Put("2", "2")
+	source.Get("1")
+
+	var buf bytes.Buffer
+	if err := source.ExportNDJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 3 {
+		t.Errorf("export should have a header and 2 lines, but got %d", lines)
+	}
+
+	target := NewCache(3, LFU)
+	imported, err := target.ImportNDJSON(&buf)
+	if err != nil || imported != 2 {
+		t.Fatalf("2 entries should be imported, but got %d, %v", imported, err)
+	}
+	if count, _ := target.AccessCount("1"); count != 2 {
+		t.Errorf("access count of key = 1 should be 2, but got %d", count)
+	}
+	test(t, target, [][]interface{}{
+		{"Get", "1", "1"},
+		{"Get", "2", "2"},
+	})
+
+	imported, err = target.ImportNDJSON(strings.NewReader(`{"key":"3","value":"3"}` + "\n" + `{"key":`))
+	if err == nil || imported != 1 {
+		t.Errorf("malformed line should fail after 1 entry, but got %d, %v", imported, err)
+	}
+
+	imported, err = target.ImportNDJSON(strings.NewReader(`{"key":"4","value":"4"}` + "\n" + `{"value":"5"}` + "\n"))
+	if err == nil || imported != 1 || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Errorf("line 2 without a key should fail after 1 entry, but got %d, %v", imported, err)
+	}
+}
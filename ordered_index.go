@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// orderedIndexMaxLevel bounds the levels of the skip list, enough for 2^32 keys
+const orderedIndexMaxLevel = 32
+
+// orderedIndex keeps the resident keys sorted in a skip list, inserts, removals and seeks are
+// O(log n) on average
+type orderedIndex struct {
+	head  indexNode
+	level int
+	rand  *rand.Rand
+}
+
+type indexNode struct {
+	key  CacheKey
+	next []*indexNode
+}
+
+func newOrderedIndex() *orderedIndex {
+	index := &orderedIndex{level: 1}
+	index.head.next = make([]*indexNode, orderedIndexMaxLevel)
+	index.rand = rand.New(rand.NewSource(1))
+	return index
+}
+
+// seek returns, for every level, the last node with a key below key
+func (i *orderedIndex) seek(key CacheKey) [orderedIndexMaxLevel]*indexNode {
+	var path [orderedIndexMaxLevel]*indexNode
+	node := &i.head
+	for level := i.level - 1; level >= 0; level-- {
+		for node.next[level] != nil && node.next[level].key < key {
+			node = node.next[level]
+		}
+		path[level] = node
+	}
+	return path
+}
+
+func (i *orderedIndex) add(key CacheKey) {
+	path := i.seek(key)
+	if next := path[0].next[0]; next != nil && next.key == key {
+		return
+	}
+	level := 1
+	for level < orderedIndexMaxLevel && i.rand.Intn(4) == 0 {
+		level++
+	}
+	for ; i.level < level; i.level++ {
+		path[i.level] = &i.head
+	}
+	node := &indexNode{key: key, next: make([]*indexNode, level)}
+	for n := 0; n < level; n++ {
+		node.next[n] = path[n].next[n]
+		path[n].next[n] = node
+	}
+}
+
+func (i *orderedIndex) remove(key CacheKey) {
+	path := i.seek(key)
+	node := path[0].next[0]
+	if node == nil || node.key != key {
+		return
+	}
+	for n := range node.next {
+		path[n].next[n] = node.next[n]
+	}
+	for i.level > 1 && i.head.next[i.level-1] == nil {
+		i.level--
+	}
+}
+
+// ascend calls fn for the keys from key onwards in order until fn returns false, fn must not
+// change the index
+func (i *orderedIndex) ascend(key CacheKey, fn func(CacheKey) bool) {
+	for node := i.seek(key)[0].next[0]; node != nil; node = node.next[0] {
+		if !fn(node.key) {
+			return
+		}
+	}
+}
+
+// EnableOrderedIndex keeps the resident keys in sorted order so that GetRange and KeysAfter can
+// serve range reads from the cache
+func (c *Cache) EnableOrderedIndex() {
+	c.index = newOrderedIndex()
+	for key := range c.data {
+		c.index.add(key)
+	}
+}
+
+// GetRange returns the entries with from <= key < to in key order, each entry returned counts as
+// a hit. Keys missing from the cache are not looked up, a range read can't tell them apart from
+// keys that don't exist
+func (c *Cache) GetRange(from, to CacheKey) ([]Entry, error) {
+	if c.index == nil {
+		return nil, errors.New("ordered index not enabled")
+	}
+	from, to = c.canonical(from), c.canonical(to)
+	var keys []CacheKey
+	c.index.ascend(from, func(key CacheKey) bool {
+		if key >= to {
+			return false
+		}
+		keys = append(keys, key)
+		return true
+	})
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	entries := make([]Entry, 0, len(keys))
+	for _, key := range keys {
+		if c.tombstoned(key) || c.outdated(key) {
+			continue
+		}
+		c.ops++
+		c.policy.Access(key)
+		c.hit(key)
+		entries = append(entries, Entry{key, c.data[key]})
+	}
+	return entries, nil
+}
+
+// KeysAfter returns up to limit resident keys greater than after in key order, pass the last key
+// of a page to get the next one. It doesn't touch the policy or the statistics
+func (c *Cache) KeysAfter(after CacheKey, limit int) ([]CacheKey, error) {
+	if c.index == nil {
+		return nil, errors.New("ordered index not enabled")
+	}
+	if limit < 0 {
+		return nil, errors.New("limit must not be negative")
+	}
+	after = c.canonical(after)
+	keys := make([]CacheKey, 0, limit)
+	c.index.ascend(after, func(key CacheKey) bool {
+		if len(keys) >= limit {
+			return false
+		}
+		if _, dead := c.tombstones[key]; dead || key == after || c.meta[key].epoch < c.epoch {
+			return true
+		}
+		keys = append(keys, key)
+		return true
+	})
+	return keys, nil
+}
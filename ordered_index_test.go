@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestGetRange(t *testing.T) {
+	cache := NewCache(3, LRU)
+	if _, err := cache.GetRange("a", "z"); err == nil {
+		t.Errorf("GetRange should fail without an ordered index")
+	}
+	cache.Put("b", "2")
+	cache.EnableOrderedIndex()
+	cache.Put("a", "1")
+	cache.Put("c", "3")
+
+	entries, err := cache.GetRange("a", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Entry{{"a", "1"}, {"b", "2"}}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("GetRange = %v, but want %v", entries, want)
+	}
+	if stats := cache.Stats(); stats.Hits != 2 {
+		t.Errorf("hits = %d, but want 2", stats.Hits)
+	}
+
+	cache.Put("d", "4") // c is the least recently used
+	entries, _ = cache.GetRange("b", "e")
+	want = []Entry{{"b", "2"}, {"d", "4"}}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("GetRange = %v, but want %v", entries, want)
+	}
+	cache.Delete("b")
+	if entries, _ = cache.GetRange("a", "c"); !reflect.DeepEqual(entries, []Entry{{"a", "1"}}) {
+		t.Errorf("GetRange = %v, but want [{a 1}]", entries)
+	}
+}
+
+func TestKeysAfter(t *testing.T) {
+	cache := NewCache(5, FIFO)
+	cache.EnableOrderedIndex()
+	for _, key := range []CacheKey{"e", "b", "d", "a", "c"} {
+		cache.Put(key, string(key))
+	}
+
+	var pages [][]CacheKey
+	var after CacheKey
+	for {
+		keys, _ := cache.KeysAfter(after, 2)
+		if len(keys) == 0 {
+			break
+		}
+		pages = append(pages, keys)
+		after = keys[len(keys)-1]
+	}
+	want := [][]CacheKey{{"a", "b"}, {"c", "d"}, {"e"}}
+	if !reflect.DeepEqual(pages, want) {
+		t.Errorf("pages = %v, but want %v", pages, want)
+	}
+	if stats := cache.Stats(); stats.Hits != 0 {
+		t.Errorf("KeysAfter should not record hits, but got %d", stats.Hits)
+	}
+	if _, err := cache.KeysAfter("", -1); err == nil {
+		t.Errorf("negative limit should fail")
+	}
+}
+
+func TestOrderedIndex(t *testing.T) {
+	index := newOrderedIndex()
+	resident := map[CacheKey]bool{}
+	r := rand.New(rand.NewSource(42))
+	for i := 0; i < 5000; i++ {
+		key := CacheKey(fmt.Sprint(r.Intn(1000)))
+		if r.Intn(3) == 0 {
+			index.remove(key)
+			delete(resident, key)
+		} else {
+			index.add(key)
+			resident[key] = true
+		}
+	}
+
+	want := make([]CacheKey, 0, len(resident))
+	for key := range resident {
+		want = append(want, key)
+	}
+	sortKeys(want)
+	var got []CacheKey
+	index.ascend("", func(key CacheKey) bool {
+		got = append(got, key)
+		return true
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("index should hold the %d resident keys in order, but got %d keys", len(want), len(got))
+	}
+}
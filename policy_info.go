@@ -0,0 +1,53 @@
+package cache
+
+import "errors"
+
+// PolicyTraits describes a built-in policy for tools recommending one. Complexities are given for
+// n resident keys, f is the number of distinct access frequencies
+type PolicyTraits struct {
+	Name string
+	// VictimComplexity is the cost of electing a victim without protections, a victim filter or
+	// sticky window makes the cache walk a bounded number of candidates on top of it
+	VictimComplexity string
+	// AccessComplexity is the cost of recording a hit
+	AccessComplexity string
+	// EntryOverhead is the approximate policy bookkeeping per resident key on 64-bit platforms, in
+	// bytes, not counting the key and the value themselves
+	EntryOverhead int
+	// ScanResistant policies keep frequently used keys when a scan touches many keys once
+	ScanResistant bool
+	// Adaptive policies tune themselves to the workload without configuration
+	Adaptive bool
+	Notes    string
+}
+
+var policyTraits = map[PolicyType]PolicyTraits{
+	FIFO: {"fifo", "O(1)", "O(1)", 104, false, false,
+		"ignores accesses, a baseline for workloads without locality"},
+	LRU: {"lru", "O(1)", "O(1)", 104, false, false,
+		"good default for recency driven workloads, a single scan flushes it"},
+	MRU: {"mru", "O(1)", "O(1)", 104, true, false,
+		"evicts the most recently used key, for cyclic scans larger than the cache"},
+	LFU: {"lfu", "O(f), O(n) when a decay is due", "O(1), O(n) when a decay or halving is due",
+		120, true, false,
+		"keeps popular keys, needs decay or halving when popularity shifts, candidates sort the f frequencies"},
+	CLOCK: {"clock", "O(n) worst case", "O(1)", 104, false, false,
+		"approximates LRU with a reference bit, accesses only set a flag"},
+	SLRU: {"slru", "O(1)", "O(1)", 120, true, false,
+		"keys seen once stay in probation, tune the protected ratio to the workload"},
+	LIRS: {"lirs", "O(1) amortized", "O(1) amortized", 232, true, true,
+		"ranks keys by reuse distance, keeps ghosts of up to as many evicted keys as resident ones"},
+	RANDOM: {"random", "O(1)", "O(1)", 48, false, false,
+		"evicts a uniformly random key, a baseline for workloads without locality"},
+	GDSF: {"gdsf", "O(log n)", "O(log n)", 112, true, false,
+		"weighs frequency by miss cost over size, use PutWithCost for objects of varying size and cost"},
+}
+
+// PolicyInfo returns the characteristics of a built-in policy
+func PolicyInfo(policy PolicyType) (PolicyTraits, error) {
+	traits, ok := policyTraits[policy]
+	if !ok {
+		return PolicyTraits{}, errors.New("unknown policy type")
+	}
+	return traits, nil
+}
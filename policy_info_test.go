@@ -0,0 +1,21 @@
+package cache
+
+import "testing"
+
+func TestPolicyInfo(t *testing.T) {
+	for _, policy := range []PolicyType{FIFO, LRU, LFU, CLOCK, SLRU, LIRS, GDSF, MRU, RANDOM} {
+		traits, err := PolicyInfo(policy)
+		if err != nil {
+			t.Fatalf("policy = %d: %v", policy, err)
+		}
+		if name := policyName(GetCachePolicy(policy)); traits.Name != name {
+			t.Errorf("policy = %d, name = %s, but want %s", policy, traits.Name, name)
+		}
+		if traits.EntryOverhead <= 0 {
+			t.Errorf("policy = %s should report its per-entry overhead", traits.Name)
+		}
+	}
+	if _, err := PolicyInfo(0); err == nil {
+		t.Errorf("PolicyInfo should fail for an unknown policy type")
+	}
+}
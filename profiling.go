@@ -0,0 +1,45 @@
+package cache
+
+import "runtime/pprof"
+
+// SetName names the cache in profiles: the goroutines started by the cache, such as callback
+// workers, carry the pprof labels cache=<name> and policy=<policy>, so CPU profiles of services
+// embedding several caches attribute time to the right instance
+func (c *Cache) SetName(name string) {
+	c.name = name
+	c.startDispatcher()
+}
+
+func (c *Cache) profilerLabels() pprof.LabelSet {
+	return pprof.Labels("cache", c.name, "policy", policyName(basePolicy(c.policy)))
+}
+
+// policyName returns a short name for the built-in policies
+func policyName(policy CachePolicy) string {
+	switch policy.(type) {
+	case *FIFOPolicy:
+		return "fifo"
+	case *LRUPolicy:
+		return "lru"
+	case *LFUPolicy:
+		return "lfu"
+	case *ClockPolicy:
+		return "clock"
+	case *SLRUPolicy:
+		return "slru"
+	case *LIRSPolicy:
+		return "lirs"
+	case *MRUPolicy:
+		return "mru"
+	case *RandomPolicy:
+		return "random"
+	case *GDSFPolicy:
+		return "gdsf"
+	case *SketchLFUPolicy:
+		return "sketch-lfu"
+	case *AsyncPolicy:
+		return "async"
+	default:
+		return "custom"
+	}
+}
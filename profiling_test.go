@@ -0,0 +1,22 @@
+package cache
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+)
+
+func TestProfilerLabels(t *testing.T) {
+	cache := NewCache(1, LRU)
+	cache.SetName("users")
+
+	labels := map[string]string{}
+	ctx := pprof.WithLabels(context.Background(), cache.profilerLabels())
+	pprof.ForLabels(ctx, func(key, value string) bool {
+		labels[key] = value
+		return true
+	})
+	if labels["cache"] != "users" || labels["policy"] != "lru" {
+		t.Errorf("labels should be cache=users policy=lru, but got %v", labels)
+	}
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RANDOM evicts a uniformly random resident key, a baseline with almost no bookkeeping for
+// workloads without locality
+type RandomPolicy struct {
+	keys     []CacheKey
+	keyIndex map[CacheKey]int
+	rand     *rand.Rand
+}
+
+// RandomOption configures a RandomPolicy
+type RandomOption func(*RandomPolicy)
+
+// WithSeed makes the sequence of victims reproducible, e.g. for simulations
+func WithSeed(seed int64) RandomOption {
+	return func(p *RandomPolicy) {
+		p.rand = rand.New(rand.NewSource(seed))
+	}
+}
+
+func NewRandomPolicy(opts ...RandomOption) CachePolicy {
+	policy := &RandomPolicy{}
+	policy.keyIndex = make(map[CacheKey]int)
+	policy.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	for _, opt := range opts {
+		opt(policy)
+	}
+	return policy
+}
+
+func (p *RandomPolicy) Victim() CacheKey {
+	key := p.keys[p.rand.Intn(len(p.keys))]
+	p.Remove(key)
+	return key
+}
+
+func (p *RandomPolicy) Add(key CacheKey) {
+	p.keyIndex[key] = len(p.keys)
+	p.keys = append(p.keys, key)
+}
+
+// Remove moves the last key into the slot of the removed one
+func (p *RandomPolicy) Remove(key CacheKey) {
+	index, ok := p.keyIndex[key]
+	if !ok {
+		return
+	}
+	last := p.keys[len(p.keys)-1]
+	p.keys[index] = last
+	p.keyIndex[last] = index
+	p.keys = p.keys[:len(p.keys)-1]
+	delete(p.keyIndex, key)
+}
+
+func (p *RandomPolicy) Access(key CacheKey) {}
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestRandomPolicy(t *testing.T) {
+	evictions := func(seed int64) []CacheKey {
+		cache := NewCacheWithPolicy(10, NewRandomPolicy(WithSeed(seed)))
+		for i := 0; i < 10; i++ {
+			cache.Put(CacheKey(fmt.Sprint(i)), "v")
+		}
+		cache.Delete("3")
+		cache.Put("3", "v")
+		var victims []CacheKey
+		for _, entry := range cache.EvictN(10) {
+			victims = append(victims, entry.Key)
+		}
+		return victims
+	}
+
+	victims := evictions(1)
+	if len(victims) != 10 {
+		t.Fatalf("evicted %d keys, but want 10", len(victims))
+	}
+	if !reflect.DeepEqual(victims, evictions(1)) {
+		t.Errorf("the same seed should evict in the same order")
+	}
+	counts := make(map[CacheKey]int)
+	for seed := int64(0); seed < 1000; seed++ {
+		counts[evictions(seed)[0]]++
+	}
+	for key, count := range counts {
+		if count < 50 || count > 150 {
+			t.Errorf("key %s was the first victim %d times out of 1000, but want about 100", key, count)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// RotatingCache keeps entries in a fixed number of time buckets. Put writes to the newest bucket
+// and every interval the oldest bucket is dropped as a whole, so eviction is O(1) regardless of
+// how many entries expire together. Useful when recency is strictly time based, like dedup windows
+type RotatingCache struct {
+	buckets  []CacheData
+	head     int // index of the newest bucket
+	interval time.Duration
+	rotated  time.Time
+	now      func() time.Time
+}
+
+// NewRotatingCache creates a cache keeping entries for between buckets-1 and buckets intervals,
+// both must be positive
+func NewRotatingCache(buckets int, interval time.Duration) (*RotatingCache, error) {
+	if buckets <= 0 {
+		return nil, errors.New("buckets must be positive")
+	}
+	if interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+	cache := &RotatingCache{}
+	cache.buckets = make([]CacheData, buckets)
+	for i := range cache.buckets {
+		cache.buckets[i] = make(CacheData)
+	}
+	cache.interval = interval
+	cache.now = time.Now
+	cache.rotated = cache.now()
+	return cache, nil
+}
+
+func (c *RotatingCache) Put(key CacheKey, value string) {
+	c.rotate()
+	for i, bucket := range c.buckets {
+		if i != c.head {
+			delete(bucket, key)
+		}
+	}
+	c.buckets[c.head][key] = value
+}
+
+func (c *RotatingCache) Get(key CacheKey) (*string, error) {
+	c.rotate()
+	for i := 0; i < len(c.buckets); i++ {
+		bucket := c.buckets[(c.head-i+len(c.buckets))%len(c.buckets)]
+		if value, ok := bucket[key]; ok {
+			return &value, nil
+		}
+	}
+
+	return nil, errors.New("key not found")
+}
+
+func (c *RotatingCache) Len() int {
+	c.rotate()
+	size := 0
+	for _, bucket := range c.buckets {
+		size += len(bucket)
+	}
+	return size
+}
+
+// rotate drops one bucket for every interval elapsed since the last rotation
+func (c *RotatingCache) rotate() {
+	elapsed := c.now().Sub(c.rotated)
+	if elapsed < c.interval {
+		return
+	}
+
+	steps := int(elapsed / c.interval)
+	c.rotated = c.rotated.Add(time.Duration(steps) * c.interval)
+	if steps > len(c.buckets) {
+		steps = len(c.buckets)
+	}
+	for i := 0; i < steps; i++ {
+		c.head = (c.head + 1) % len(c.buckets)
+		c.buckets[c.head] = make(CacheData)
+	}
+}
@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRotatingCache(t *testing.T) {
+	now := time.Now()
+	cache, err := NewRotatingCache(3, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.now = func() time.Time { return now }
+	cache.rotated = now
+
+	cache.Put("1", "1")
+	now = now.Add(time.Minute)
+	cache.Put("2", "2")
+	now = now.Add(time.Minute)
+	cache.Put("3", "3")
+	cache.Put("1", "one") // moves 1 to the newest bucket
+	if cache.Len() != 3 {
+		t.Errorf("len should be 3, but got %d", cache.Len())
+	}
+
+	now = now.Add(time.Minute) // the bucket that held 1 is dropped
+	if _, err := cache.Get("2"); err != nil {
+		t.Errorf("key = 2 should still be resident")
+	}
+
+	now = now.Add(time.Minute) // the bucket holding 2 is dropped
+	if _, err := cache.Get("2"); err == nil {
+		t.Errorf("key = 2 should be dropped with its bucket")
+	}
+	if value, err := cache.Get("1"); err != nil || *value != "one" {
+		t.Errorf("key = 1 should be one")
+	}
+
+	now = now.Add(10 * time.Minute) // every bucket is dropped
+	if cache.Len() != 0 {
+		t.Errorf("len should be 0, but got %d", cache.Len())
+	}
+}
+
+func TestNewRotatingCacheInvalid(t *testing.T) {
+	if _, err := NewRotatingCache(0, time.Minute); err == nil {
+		t.Errorf("buckets = 0 should fail")
+	}
+	if _, err := NewRotatingCache(3, 0); err == nil {
+		t.Errorf("interval = 0 should fail")
+	}
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"math/rand"
+	"time"
+)
+
+// EntryInfo describes a resident entry, Epoch is the epoch it was last written in
+type EntryInfo struct {
+	Key        CacheKey
+	Epoch      uint64
+	InsertedAt time.Time
+	WrittenAt  time.Time
+	Accessed   bool // hit at least once since insert
+	Source     string
+	Writer     string // see SetWriterTracking
+}
+
+// SampleKeys returns a uniform random sample of up to n resident keys in O(n), whatever the
+// cache size. Deleted and outdated entries are left out, so the sample can be smaller than n
+// even when the cache holds more keys
+func (c *Cache) SampleKeys(n int) []EntryInfo {
+	if n <= 0 {
+		return nil
+	}
+	if n > len(c.slots) {
+		n = len(c.slots)
+	}
+	// partial Fisher-Yates shuffle, swaps are recorded instead of applied to c.slots
+	swapped := make(map[int]int, n)
+	slot := func(i int) int {
+		if j, ok := swapped[i]; ok {
+			return j
+		}
+		return i
+	}
+	samples := make([]EntryInfo, 0, n)
+	for i := 0; i < n; i++ {
+		j := i + rand.Intn(len(c.slots)-i)
+		picked := slot(j)
+		swapped[j] = slot(i)
+		key := c.slots[picked]
+		if _, dead := c.tombstones[key]; dead || c.meta[key].epoch < c.epoch {
+			continue
+		}
+		samples = append(samples, c.info(key))
+	}
+	return samples
+}
+
+func (c *Cache) info(key CacheKey) EntryInfo {
+	meta := c.meta[key]
+	return EntryInfo{key, meta.epoch, meta.insertedAt, meta.writtenAt, meta.accessed, meta.source, meta.writer}
+}
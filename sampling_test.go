@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSampleKeys(t *testing.T) {
+	cache := NewCache(100, LRU)
+	for i := 0; i < 100; i++ {
+		cache.PutWithSource(CacheKey(fmt.Sprint(i)), "v", "loader")
+	}
+	cache.Delete("0")
+	cache.EvictN(10)
+
+	seen := make(map[CacheKey]int)
+	for round := 0; round < 200; round++ {
+		samples := cache.SampleKeys(10)
+		if len(samples) != 10 {
+			t.Fatalf("sample size = %d, but want 10", len(samples))
+		}
+		for _, sample := range samples {
+			if sample.Source != "loader" {
+				t.Fatalf("sample %v should carry the source label", sample)
+			}
+			seen[sample.Key]++
+		}
+	}
+	if len(seen) != 89 {
+		t.Errorf("sampled %d distinct keys, but want all 89 resident keys", len(seen))
+	}
+	for key, count := range seen {
+		if count > 60 {
+			t.Errorf("key %s sampled %d times out of 200, sampling is not uniform", key, count)
+		}
+	}
+	if samples := cache.SampleKeys(1000); len(samples) != 89 {
+		t.Errorf("sample size = %d, but want 89", len(samples))
+	}
+	if samples := cache.SampleKeys(-1); samples != nil {
+		t.Errorf("negative sample size should return nil, but got %v", samples)
+	}
+}
@@ -0,0 +1,48 @@
+package cache
+
+import "time"
+
+// FillRatio returns the size of the cache divided by its capacity
+func (c *Cache) FillRatio() float64 {
+	if c.maxSize == 0 {
+		return 0
+	}
+	return float64(c.size) / float64(c.maxSize)
+}
+
+// TimeAtFull returns the total time the cache has spent full, including the current streak
+func (c *Cache) TimeAtFull() time.Duration {
+	if c.fullSince.IsZero() {
+		return c.timeAtFull
+	}
+	return c.timeAtFull + c.now().Sub(c.fullSince)
+}
+
+// SetFullAlarm registers a callback fired once the cache has been continuously full for longer
+// than threshold, a sign that capacity or admission needs tuning. It fires once per streak and is
+// checked on writes
+func (c *Cache) SetFullAlarm(threshold time.Duration, callback func(fullFor time.Duration)) {
+	c.fullAlarm = threshold
+	c.onFull = callback
+}
+
+func (c *Cache) updateFullness() {
+	now := c.now()
+	if c.maxSize <= 0 || c.size < c.maxSize { // a cache without capacity is never full
+		if !c.fullSince.IsZero() {
+			c.timeAtFull += now.Sub(c.fullSince)
+			c.fullSince = time.Time{}
+		}
+		c.fullAlarmFired = false
+		return
+	}
+
+	if c.fullSince.IsZero() {
+		c.fullSince = now
+	}
+	fullFor := now.Sub(c.fullSince)
+	if c.onFull != nil && !c.fullAlarmFired && fullFor >= c.fullAlarm {
+		c.fullAlarmFired = true
+		c.dispatch(func() { c.onFull(fullFor) })
+	}
+}
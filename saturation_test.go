@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSaturation(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(2, LRU)
+	cache.now = func() time.Time { return now }
+	alarms := []time.Duration{}
+	cache.SetFullAlarm(time.Minute, func(fullFor time.Duration) {
+		alarms = append(alarms, fullFor)
+	})
+
+	cache.Put("1", "1")
+	if cache.FillRatio() != 0.5 || cache.TimeAtFull() != 0 {
+		t.Errorf("fill ratio should be 0.5, but got %f", cache.FillRatio())
+	}
+
+	cache.Put("2", "2")
+	now = now.Add(30 * time.Second)
+	cache.Put("3", "3")
+	now = now.Add(30 * time.Second)
+	cache.Put("4", "4") // full for a minute
+	cache.Put("5", "5") // fires once per streak
+	if len(alarms) != 1 || alarms[0] != time.Minute {
+		t.Errorf("alarm should fire once after a minute, but got %v", alarms)
+	}
+
+	cache.Delete("5")
+	now = now.Add(time.Hour)
+	if cache.FillRatio() != 0.5 || cache.TimeAtFull() != time.Minute {
+		t.Errorf("time at full should be 1m, but got %v", cache.TimeAtFull())
+	}
+}
+
+func TestSaturationOverwrite(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(1, LRU)
+	cache.now = func() time.Time { return now }
+	alarms := 0
+	cache.SetFullAlarm(time.Minute, func(time.Duration) { alarms++ })
+
+	cache.Put("1", "1")
+	now = now.Add(time.Minute)
+	cache.Put("1", "one") // an overwrite checks the alarm
+	if alarms != 1 {
+		t.Errorf("alarm should fire on an overwrite, but got %d alarms", alarms)
+	}
+
+	empty := NewCache(0, LRU)
+	empty.now = cache.now
+	empty.SetFullAlarm(0, func(time.Duration) { alarms++ })
+	empty.Put("1", "1")
+	empty.Resize(0)
+	if alarms != 1 || empty.TimeAtFull() != 0 {
+		t.Errorf("a cache without capacity should never be full")
+	}
+}
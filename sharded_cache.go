@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"errors"
+	"hash/fnv"
+	"hash/maphash"
+	"sync"
+)
+
+// HashFunc maps a key to the shard it belongs to
+type HashFunc func(CacheKey) uint64
+
+// FNVHash is the default HashFunc
+func FNVHash(key CacheKey) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	return hash.Sum64()
+}
+
+// NewMapHash returns a HashFunc based on hash/maphash with a per-process random seed, it mixes
+// keys sharing long common prefixes better than FNV
+func NewMapHash() HashFunc {
+	seed := maphash.MakeSeed()
+	return func(key CacheKey) uint64 {
+		var hash maphash.Hash
+		hash.SetSeed(seed)
+		hash.WriteString(string(key))
+		return hash.Sum64()
+	}
+}
+
+// ShardedCache spreads keys over independent caches, each guarded by its own lock, so it is safe
+// for concurrent use
+type ShardedCache struct {
+	shards []*shard
+	hash   HashFunc
+}
+
+type shard struct {
+	sync.Mutex
+	cache *Cache
+}
+
+// ShardStats describes how evenly keys are spread over the shards
+// Skew is the size of the largest shard divided by the mean shard size
+type ShardStats struct {
+	Sizes []int
+	Skew  float64
+}
+
+// NewShardedCache creates shards caches of shardSize entries each, a nil hash uses FNVHash. shards
+// must be positive
+func NewShardedCache(shards, shardSize int, policy PolicyType, hash HashFunc) (*ShardedCache, error) {
+	if shards <= 0 {
+		return nil, errors.New("shards must be positive")
+	}
+	if hash == nil {
+		hash = FNVHash
+	}
+	cache := &ShardedCache{}
+	cache.hash = hash
+	cache.shards = make([]*shard, shards)
+	for i := range cache.shards {
+		cache.shards[i] = &shard{cache: NewCache(shardSize, policy)}
+	}
+	return cache, nil
+}
+
+func (c *ShardedCache) Put(key CacheKey, value string) {
+	s := c.shard(key)
+	s.Lock()
+	defer s.Unlock()
+	s.cache.Put(key, value)
+}
+
+func (c *ShardedCache) Get(key CacheKey) (*string, error) {
+	s := c.shard(key)
+	s.Lock()
+	defer s.Unlock()
+	return s.cache.Get(key)
+}
+
+func (c *ShardedCache) Delete(key CacheKey) {
+	s := c.shard(key)
+	s.Lock()
+	defer s.Unlock()
+	s.cache.Delete(key)
+}
+
+func (c *ShardedCache) ShardStats() ShardStats {
+	stats := ShardStats{}
+	stats.Sizes = make([]int, len(c.shards))
+	total, largest := 0, 0
+	for i, s := range c.shards {
+		s.Lock()
+		stats.Sizes[i] = s.cache.size
+		s.Unlock()
+		total += stats.Sizes[i]
+		if stats.Sizes[i] > largest {
+			largest = stats.Sizes[i]
+		}
+	}
+	if total > 0 {
+		stats.Skew = float64(largest) / (float64(total) / float64(len(c.shards)))
+	}
+	return stats
+}
+
+// Stats aggregates the counters of every shard without taking the shard locks, so scraping
+// metrics never contends with the hot path
+func (c *ShardedCache) Stats() Stats {
+	total := Stats{}
+	for _, s := range c.shards {
+		stats := s.cache.Stats()
+		total.Hits += stats.Hits
+		total.Misses += stats.Misses
+		total.Evictions += stats.Evictions
+		total.PrematureEvictions += stats.PrematureEvictions
+		total.ParentHits += stats.ParentHits
+		total.RecoveredPanics += stats.RecoveredPanics
+		total.DroppedCallbacks += stats.DroppedCallbacks
+		total.OneHitWonders += stats.OneHitWonders
+		total.Rejected += stats.Rejected
+		total.WarmupBypassed += stats.WarmupBypassed
+		total.DistinctKeys += stats.DistinctKeys // shards partition the keys
+		total.MissCostSaved += stats.MissCostSaved
+		total.MissCostIncurred += stats.MissCostIncurred
+	}
+	return total
+}
+
+func (c *ShardedCache) shard(key CacheKey) *shard {
+	return c.shards[c.hash(key)%uint64(len(c.shards))]
+}
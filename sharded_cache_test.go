@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestShardedCache(t *testing.T) {
+	for _, hash := range []HashFunc{nil, NewMapHash()} {
+		cache, _ := NewShardedCache(4, 200, LRU, hash)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := i * 50; j < (i+1)*50; j++ {
+					key := CacheKey(fmt.Sprintf("tenant/users/%d", j))
+					cache.Put(key, string(key))
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		for j := 0; j < 200; j++ {
+			key := CacheKey(fmt.Sprintf("tenant/users/%d", j))
+			if value, err := cache.Get(key); err != nil || *value != string(key) {
+				t.Errorf("key = %s should be cached", key)
+			}
+		}
+
+		stats := cache.ShardStats()
+		total := 0
+		for _, size := range stats.Sizes {
+			total += size
+		}
+		if total != 200 || stats.Skew < 1 || stats.Skew > 2 {
+			t.Errorf("keys should be spread over the shards, but got %v", stats)
+		}
+	}
+}
+
+func TestShardedCacheStats(t *testing.T) {
+	cache, _ := NewShardedCache(4, 10, LRU, nil)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			key := CacheKey(fmt.Sprint(i))
+			cache.Put(key, "")
+			cache.Get(key)
+			cache.Get("missing")
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		cache.Stats() // must not race with the writer
+	}
+	<-done
+
+	stats := cache.Stats()
+	if stats.Hits != 100 || stats.Misses != 100 {
+		t.Errorf("stats should count 100 hits and 100 misses, but got %v", stats)
+	}
+}
+
+func TestNewShardedCacheInvalid(t *testing.T) {
+	if _, err := NewShardedCache(0, 10, LRU, nil); err == nil {
+		t.Errorf("shards = 0 should fail")
+	}
+}
@@ -0,0 +1,82 @@
+package cache
+
+import "container/list"
+
+// sketchLFUSamples is the number of least recently used keys compared when electing a victim
+const sketchLFUSamples = 8
+
+// SketchLFUPolicy is an approximate LFU whose frequencies live in a count-min sketch of fixed
+// size instead of per-key frequency lists. Keys are kept in recency order only, a victim is the
+// least frequently used of the sketchLFUSamples least recently used keys, ties go to the least
+// recently used one. Frequencies saturate at 15 and are halved every ten times width accesses
+type SketchLFUPolicy struct {
+	list    *list.List
+	keyNode map[CacheKey]*list.Element
+	sketch  *countMinSketch
+}
+
+// NewSketchLFUPolicy creates the policy with width counters per sketch row, about the number of
+// distinct keys expected among the recent accesses. The sketch takes width * 2 bytes
+func NewSketchLFUPolicy(width int) CachePolicy {
+	policy := &SketchLFUPolicy{}
+	policy.list = list.New()
+	policy.keyNode = make(map[CacheKey]*list.Element)
+	policy.sketch = newCountMinSketch(width, 10*width)
+	return policy
+}
+
+func (p *SketchLFUPolicy) Victim() CacheKey {
+	victim := p.list.Back()
+	frequency := p.sketch.estimate(victim.Value.(CacheKey))
+	element := victim.Prev()
+	for i := 1; i < sketchLFUSamples && element != nil; i++ {
+		if f := p.sketch.estimate(element.Value.(CacheKey)); f < frequency {
+			victim, frequency = element, f
+		}
+		element = element.Prev()
+	}
+	key := victim.Value.(CacheKey)
+	p.list.Remove(victim)
+	delete(p.keyNode, key)
+	return key
+}
+
+func (p *SketchLFUPolicy) Add(key CacheKey) {
+	p.sketch.increment(key)
+	p.keyNode[key] = p.list.PushFront(key)
+}
+
+func (p *SketchLFUPolicy) Remove(key CacheKey) {
+	node, ok := p.keyNode[key]
+	if !ok {
+		return
+	}
+	p.list.Remove(node)
+	delete(p.keyNode, key)
+}
+
+func (p *SketchLFUPolicy) Access(key CacheKey) {
+	node, ok := p.keyNode[key]
+	if !ok {
+		return
+	}
+	p.sketch.increment(key)
+	p.list.MoveToFront(node)
+}
+
+// Frequency returns the estimated frequency of a resident key, it is never below the true one
+// unless the counter saturated or was halved
+func (p *SketchLFUPolicy) Frequency(key CacheKey) (Frequency, bool) {
+	if _, ok := p.keyNode[key]; !ok {
+		return 0, false
+	}
+	return Frequency(p.sketch.estimate(key)), true
+}
+
+func (p *SketchLFUPolicy) Histogram() map[Frequency]int {
+	histogram := make(map[Frequency]int)
+	for key := range p.keyNode {
+		histogram[Frequency(p.sketch.estimate(key))]++
+	}
+	return histogram
+}
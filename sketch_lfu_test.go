@@ -0,0 +1,27 @@
+package cache
+
+import "testing"
+
+func TestSketchLFUPolicy(t *testing.T) {
+	testCase := [][]interface{}{
+		{"Put", "1", "1"},
+		{"Put", "2", "2"},
+		{"Put", "3", "3"},
+		{"Get", "1", "1"},
+		{"Get", "1", "1"},
+		{"Get", "3", "3"},
+		{"Put", "4", "4"}, // 2 is evicted, it is the least frequently used
+		{"Get", "2", nil},
+		{"Put", "5", "5"}, // 4 is evicted
+		{"Get", "4", nil},
+		{"Get", "1", "1"},
+		{"Get", "3", "3"},
+		{"Get", "5", "5"},
+	}
+
+	cache := NewCacheWithPolicy(3, NewSketchLFUPolicy(64))
+	test(t, cache, testCase)
+	if count, _ := cache.AccessCount("1"); count != 4 {
+		t.Errorf("access count of key = 1 should be 4, but got %d", count)
+	}
+}
@@ -0,0 +1,101 @@
+package cache
+
+import "container/list"
+
+// SLRU, segmented LRU: new keys enter a probationary segment and are promoted to a protected
+// segment on their second access. When the protected segment outgrows its share of the resident
+// keys its least recently used key is demoted back to probation. Victims are taken from probation
+// first, so keys seen only once can't push out keys seen repeatedly
+type SLRUPolicy struct {
+	probation      *list.List
+	protected      *list.List
+	keyNode        map[CacheKey]*list.Element
+	protectedRatio float64
+}
+
+type slruItem struct {
+	key       CacheKey
+	protected bool
+}
+
+// SLRUOption configures an SLRUPolicy
+type SLRUOption func(*SLRUPolicy)
+
+// WithProtectedRatio sets the share of the resident keys the protected segment may hold, 0.8 by
+// default
+func WithProtectedRatio(ratio float64) SLRUOption {
+	return func(p *SLRUPolicy) {
+		p.protectedRatio = ratio
+	}
+}
+
+func NewSLRUPolicy(opts ...SLRUOption) CachePolicy {
+	policy := &SLRUPolicy{}
+	policy.probation = list.New()
+	policy.protected = list.New()
+	policy.keyNode = make(map[CacheKey]*list.Element)
+	policy.protectedRatio = 0.8
+	for _, opt := range opts {
+		opt(policy)
+	}
+	return policy
+}
+
+func (p *SLRUPolicy) Victim() CacheKey {
+	segment := p.probation
+	if segment.Len() == 0 {
+		segment = p.protected
+	}
+	element := segment.Back()
+	segment.Remove(element)
+	key := element.Value.(*slruItem).key
+	delete(p.keyNode, key)
+	return key
+}
+
+func (p *SLRUPolicy) Add(key CacheKey) {
+	p.keyNode[key] = p.probation.PushFront(&slruItem{key: key})
+}
+
+func (p *SLRUPolicy) Remove(key CacheKey) {
+	node, ok := p.keyNode[key]
+	if !ok {
+		return
+	}
+	p.segment(node).Remove(node)
+	delete(p.keyNode, key)
+}
+
+func (p *SLRUPolicy) Access(key CacheKey) {
+	node, ok := p.keyNode[key]
+	if !ok {
+		return
+	}
+	item := node.Value.(*slruItem)
+	if item.protected {
+		p.protected.MoveToFront(node)
+		return
+	}
+	p.probation.Remove(node)
+	item.protected = true
+	p.keyNode[key] = p.protected.PushFront(item)
+	if float64(p.protected.Len()) > p.protectedRatio*float64(len(p.keyNode)) {
+		p.demote()
+	}
+}
+
+// demote moves the least recently used protected key to the front of probation
+func (p *SLRUPolicy) demote() {
+	element := p.protected.Back()
+	p.protected.Remove(element)
+	item := element.Value.(*slruItem)
+	item.protected = false
+	p.keyNode[item.key] = p.probation.PushFront(item)
+}
+
+func (p *SLRUPolicy) segment(node *list.Element) *list.List {
+	if node.Value.(*slruItem).protected {
+		return p.protected
+	}
+	return p.probation
+}
@@ -0,0 +1,42 @@
+package cache
+
+import "testing"
+
+func TestSLRUPolicy(t *testing.T) {
+	testCase := [][]interface{}{
+		{"Put", "1", "1"},
+		{"Put", "2", "2"},
+		{"Put", "3", "3"},
+		{"Put", "4", "4"},
+		{"Get", "1", "1"}, // 1 is promoted
+		{"Get", "2", "2"}, // 2 is promoted
+		{"Get", "3", "3"}, // 3 is promoted, 1 is demoted to probation
+		{"Put", "5", "5"}, // 4 is evicted
+		{"Get", "4", nil},
+		{"Put", "6", "6"}, // 1 is evicted
+		{"Get", "1", nil},
+		{"Put", "7", "7"}, // 5 is evicted, the scan can't reach the protected keys
+		{"Put", "8", "8"}, // 6 is evicted
+		{"Get", "2", "2"},
+		{"Get", "3", "3"},
+		{"Get", "5", nil},
+		{"Get", "6", nil},
+	}
+
+	cache := NewCacheWithPolicy(4, NewSLRUPolicy(WithProtectedRatio(0.5)))
+	test(t, cache, testCase)
+}
+
+func TestSLRUPolicyProtectedVictim(t *testing.T) {
+	cache := NewCache(2, SLRU)
+	test(t, cache, [][]interface{}{
+		{"Put", "1", "1"},
+		{"Put", "2", "2"},
+		{"Get", "1", "1"},
+	})
+	cache.Delete("2")
+	cache.Resize(0) // only protected keys are left
+	test(t, cache, [][]interface{}{
+		{"Get", "1", nil},
+	})
+}
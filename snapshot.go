@@ -0,0 +1,52 @@
+package cache
+
+import "sort"
+
+// Snapshot is a point-in-time copy of the cache data
+type Snapshot map[CacheKey]string
+
+// SnapshotDiff lists the keys that differ between two snapshots, each slice sorted
+type SnapshotDiff struct {
+	Added   []CacheKey
+	Removed []CacheKey
+	Changed []CacheKey
+}
+
+func (c *Cache) Snapshot() Snapshot {
+	snapshot := make(Snapshot, len(c.data))
+	for key, value := range c.data {
+		if c.meta[key].epoch < c.epoch {
+			continue
+		}
+		snapshot[key] = value
+	}
+	c.lastSnapshot = c.now()
+	return snapshot
+}
+
+// DiffSnapshots reports the keys added, removed and changed going from a to b
+func DiffSnapshots(a, b Snapshot) SnapshotDiff {
+	diff := SnapshotDiff{}
+	for key, value := range b {
+		old, ok := a[key]
+		if !ok {
+			diff.Added = append(diff.Added, key)
+		} else if old != value {
+			diff.Changed = append(diff.Changed, key)
+		}
+	}
+	for key := range a {
+		if _, ok := b[key]; !ok {
+			diff.Removed = append(diff.Removed, key)
+		}
+	}
+
+	sortKeys(diff.Added)
+	sortKeys(diff.Removed)
+	sortKeys(diff.Changed)
+	return diff
+}
+
+func sortKeys(keys []CacheKey) {
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+}
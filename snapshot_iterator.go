@@ -0,0 +1,112 @@
+package cache
+
+// snapshotBucketSize is the number of slots copied at once when a write touches a part of the
+// cache an iterator hasn't visited yet
+const snapshotBucketSize = 64
+
+// SnapshotIterator walks the entries as of the call to IterateSnapshot while writes proceed. The
+// resident keys are split in buckets of slots, a bucket is only copied when a write is about to
+// touch it before the iterator got there, so a large cache streams without being copied whole
+type SnapshotIterator struct {
+	cache   *Cache
+	epoch   uint64
+	end     int             // number of slots at the start
+	bucket  int             // bucket of the entries in current
+	copied  map[int][]Entry // buckets copied before a write touched them
+	current []Entry
+}
+
+// IterateSnapshot starts an iteration over the entries as of now, deleted and outdated entries
+// are left out. Next is called under the same lock as the other methods, writes can go on between
+// calls. Close the iterator when stopping early, writes pay for every open iterator
+func (c *Cache) IterateSnapshot() *SnapshotIterator {
+	it := &SnapshotIterator{cache: c, epoch: c.epoch, end: len(c.slots), bucket: -1}
+	it.copied = make(map[int][]Entry)
+	if c.iterators == nil {
+		c.iterators = make(map[*SnapshotIterator]bool)
+	}
+	c.iterators[it] = true
+	return it
+}
+
+// Next returns the next entry, false once every entry was returned
+func (it *SnapshotIterator) Next() (Entry, bool) {
+	for len(it.current) == 0 {
+		if it.cache == nil || (it.bucket+1)*snapshotBucketSize >= it.end {
+			it.Close()
+			return Entry{}, false
+		}
+		it.bucket++
+		if entries, ok := it.copied[it.bucket]; ok {
+			it.current = entries
+			delete(it.copied, it.bucket)
+		} else {
+			it.current = it.copy(it.bucket)
+		}
+	}
+	entry := it.current[0]
+	it.current = it.current[1:]
+	return entry, true
+}
+
+func (it *SnapshotIterator) Close() {
+	if it.cache != nil {
+		delete(it.cache.iterators, it)
+		it.cache = nil
+		it.copied = nil
+		it.current = nil
+	}
+}
+
+// copy returns the live entries of a bucket no write touched since the start
+func (it *SnapshotIterator) copy(bucket int) []Entry {
+	c := it.cache
+	start, end := bucket*snapshotBucketSize, (bucket+1)*snapshotBucketSize
+	if end > it.end {
+		end = it.end
+	}
+	entries := make([]Entry, 0, end-start)
+	for _, key := range c.slots[start:end] {
+		if _, dead := c.tombstones[key]; dead || c.meta[key].epoch < it.epoch {
+			continue
+		}
+		entries = append(entries, Entry{key, c.data[key]})
+	}
+	return entries
+}
+
+// touch is called before a write changes the value, epoch or tombstone of a resident key
+func (c *Cache) touch(key CacheKey) {
+	if len(c.iterators) > 0 {
+		c.touchSlot(c.meta[key].slot)
+	}
+}
+
+// touchSlot copies the bucket of slot for the iterators that haven't visited it yet
+func (c *Cache) touchSlot(slot int) {
+	for it := range c.iterators {
+		bucket := slot / snapshotBucketSize
+		if slot >= it.end || bucket <= it.bucket {
+			continue
+		}
+		if _, ok := it.copied[bucket]; !ok {
+			it.copied[bucket] = it.copy(bucket)
+		}
+	}
+}
+
+func (c *Cache) addSlot(key CacheKey) {
+	c.meta[key].slot = len(c.slots)
+	c.slots = append(c.slots, key)
+}
+
+// removeSlot moves the last key into the slot of the removed one
+func (c *Cache) removeSlot(key CacheKey) {
+	slot := c.meta[key].slot
+	c.touchSlot(slot)
+	c.touchSlot(len(c.slots) - 1)
+	last := c.slots[len(c.slots)-1]
+	c.slots[slot] = last
+	c.meta[last].slot = slot
+	c.slots = c.slots[:len(c.slots)-1]
+}
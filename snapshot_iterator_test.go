@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestIterateSnapshot(t *testing.T) {
+	cache := NewCache(200, FIFO)
+	cache.SetTombstoneGrace(time.Minute)
+	want := map[CacheKey]string{}
+	for i := 0; i < 200; i++ {
+		key := CacheKey(fmt.Sprint(i))
+		cache.Put(key, "old")
+		want[key] = "old"
+	}
+	cache.Delete("0")
+	delete(want, "0")
+
+	it := cache.IterateSnapshot()
+	seen := map[CacheKey]string{}
+	for i := 0; ; i++ {
+		entry, ok := it.Next()
+		if !ok {
+			break
+		}
+		seen[entry.Key] = entry.Value
+		// writes between calls, new keys evict the oldest ones
+		cache.Put(CacheKey(fmt.Sprint(199-i)), "new")
+		cache.Put(CacheKey(fmt.Sprint("new", i)), "new")
+		cache.Delete(CacheKey(fmt.Sprint(100 + i%100)))
+		if i == 50 {
+			cache.NewEpoch()
+		}
+	}
+	if len(seen) != len(want) {
+		t.Errorf("iteration should see %d entries, but got %d", len(want), len(seen))
+	}
+	for key, value := range want {
+		if seen[key] != value {
+			t.Errorf("key = %s should be seen as %q, but got %q", key, value, seen[key])
+		}
+	}
+	if len(cache.iterators) != 0 {
+		t.Errorf("exhausted iterator should be closed")
+	}
+
+	it = cache.IterateSnapshot()
+	it.Next()
+	it.Close()
+	if _, ok := it.Next(); ok || len(cache.iterators) != 0 {
+		t.Errorf("closed iterator should stop")
+	}
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	cache := NewCache(3, LRU)
+	cache.Put("1", "1")
+	cache.Put("2", "2")
+	cache.Put("3", "3")
+	before := cache.Snapshot()
+
+	cache.Put("4", "4") // 1 is evicted
+	cache.data["2"] = "two"
+	after := cache.Snapshot()
+
+	diff := DiffSnapshots(before, after)
+	if !reflect.DeepEqual(diff.Added, []CacheKey{"4"}) {
+		t.Errorf("added should be [4], but got %v", diff.Added)
+	}
+	if !reflect.DeepEqual(diff.Removed, []CacheKey{"1"}) {
+		t.Errorf("removed should be [1], but got %v", diff.Removed)
+	}
+	if !reflect.DeepEqual(diff.Changed, []CacheKey{"2"}) {
+		t.Errorf("changed should be [2], but got %v", diff.Changed)
+	}
+
+	diff = DiffSnapshots(after, after)
+	if len(diff.Added)+len(diff.Removed)+len(diff.Changed) != 0 {
+		t.Errorf("diff of identical snapshots should be empty, but got %v", diff)
+	}
+}
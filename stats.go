@@ -0,0 +1,263 @@
+package cache
+
+import (
+	"container/list"
+	"sync/atomic"
+	"time"
+)
+
+// Stats holds the cache counters
+// PrematureEvictions counts misses on keys evicted less than the premature eviction window ago,
+// it stays at zero unless the window is set
+// ParentHits counts misses served by a parent cache
+// RecoveredPanics counts panics recovered from user callbacks
+// DroppedCallbacks counts callbacks shed because too many were in flight
+// OneHitWonders counts evicted entries that were never hit after their insert
+// Rejected counts TryPut calls that were turned down, keys refused by the admission policy and
+// keys not stored because the victim filter vetoed every candidate
+// WarmupBypassed counts keys not admitted because they were requested only once during warm-up
+// DistinctKeys estimates the distinct keys requested recently, it stays at zero unless
+// EnableDistinctKeys was called
+// MissCostSaved and MissCostIncurred total the miss costs set with SetMissCost, see MissCosts
+type Stats struct {
+	Hits               int
+	Misses             int
+	Evictions          int
+	PrematureEvictions int
+	ParentHits         int
+	RecoveredPanics    int
+	DroppedCallbacks   int
+	OneHitWonders      int
+	Rejected           int
+	WarmupBypassed     int
+	DistinctKeys       int
+	MissCostSaved      float64
+	MissCostIncurred   float64
+}
+
+// OneHitWonderRatio returns the fraction of evicted entries never hit after their insert, a high
+// ratio means admission control would keep those entries from pushing out useful ones
+func (s Stats) OneHitWonderRatio() float64 {
+	if s.Evictions == 0 {
+		return 0
+	}
+	return float64(s.OneHitWonders) / float64(s.Evictions)
+}
+
+func (c *Cache) Stats() Stats {
+	stats := c.stats.load()
+	stats.DistinctKeys = c.distinctEstimate()
+	return stats
+}
+
+// counters backs Stats, every counter is updated atomically so that reading them never blocks
+// writers, even when the cache is guarded by a lock
+type counters struct {
+	hits               int64
+	misses             int64
+	evictions          int64
+	prematureEvictions int64
+	parentHits         int64
+	recoveredPanics    int64
+	droppedCallbacks   int64
+	oneHitWonders      int64
+	rejected           int64
+	warmupBypassed     int64
+	missCostSaved      uint64 // float64 bits
+	missCostIncurred   uint64 // float64 bits
+}
+
+func (c *counters) load() Stats {
+	return Stats{
+		Hits:               int(atomic.LoadInt64(&c.hits)),
+		Misses:             int(atomic.LoadInt64(&c.misses)),
+		Evictions:          int(atomic.LoadInt64(&c.evictions)),
+		PrematureEvictions: int(atomic.LoadInt64(&c.prematureEvictions)),
+		ParentHits:         int(atomic.LoadInt64(&c.parentHits)),
+		RecoveredPanics:    int(atomic.LoadInt64(&c.recoveredPanics)),
+		DroppedCallbacks:   int(atomic.LoadInt64(&c.droppedCallbacks)),
+		OneHitWonders:      int(atomic.LoadInt64(&c.oneHitWonders)),
+		Rejected:           int(atomic.LoadInt64(&c.rejected)),
+		WarmupBypassed:     int(atomic.LoadInt64(&c.warmupBypassed)),
+		MissCostSaved:      loadFloat(&c.missCostSaved),
+		MissCostIncurred:   loadFloat(&c.missCostIncurred),
+	}
+}
+
+// SourceStats breaks the cache usage down by the source label given to PutWithSource, entries
+// stored with Put have the empty label
+type SourceStats struct {
+	Entries   int
+	Hits      int
+	Evictions int
+}
+
+// SourceStats returns the usage of each source label seen so far
+func (c *Cache) SourceStats() map[string]SourceStats {
+	result := make(map[string]SourceStats, len(c.sources))
+	for source, stats := range c.sources {
+		result[source] = *stats
+	}
+	for _, meta := range c.meta {
+		stats := result[meta.source]
+		stats.Entries++
+		result[meta.source] = stats
+	}
+	return result
+}
+
+func (c *Cache) sourceStats(source string) *SourceStats {
+	if c.sources == nil {
+		c.sources = make(map[string]*SourceStats)
+	}
+	stats, ok := c.sources[source]
+	if !ok {
+		stats = &SourceStats{}
+		c.sources[source] = stats
+	}
+	return stats
+}
+
+// SetPrematureEvictionWindow starts remembering evicted keys for window so that a miss on a
+// recently evicted key is counted as a premature eviction, a hint that the cache is too small.
+// At most as many keys as the cache capacity are remembered
+func (c *Cache) SetPrematureEvictionWindow(window time.Duration) {
+	if window <= 0 {
+		c.evicted = nil
+		return
+	}
+	c.evicted = newEvictionHistory(window, c.maxSize)
+}
+
+// HitRatio returns the hit ratio over the last window, up to an hour, in steps of ten seconds.
+// Unlike the lifetime counters it shows recent regressions, it returns 0 without requests
+func (c *Cache) HitRatio(window time.Duration) float64 {
+	hits, misses := c.window.sum(c.now(), window)
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+func (c *Cache) hit(key CacheKey) {
+	atomic.AddInt64(&c.stats.hits, 1)
+	c.meta[key].accessed = true
+	c.sourceStats(c.meta[key].source).Hits++
+	c.window.record(c.now(), true)
+	c.recordMissCost(key, true)
+}
+
+func (c *Cache) miss(key CacheKey) {
+	atomic.AddInt64(&c.stats.misses, 1)
+	if c.doorkeeper != nil {
+		c.doorkeeper.increment(key)
+	}
+	c.window.record(c.now(), false)
+	c.recordMissCost(key, false)
+	if c.evicted != nil && c.evicted.forget(key, c.now()) {
+		atomic.AddInt64(&c.stats.prematureEvictions, 1)
+	}
+}
+
+func (c *Cache) recordEviction(key CacheKey) {
+	if c.evicted != nil {
+		c.evicted.add(key, c.now())
+	}
+}
+
+// evictionHistory remembers recently evicted keys, most recent at the front
+type evictionHistory struct {
+	window  time.Duration
+	maxSize int
+	list    *list.List
+	keyNode map[CacheKey]*list.Element
+}
+
+type evictionRecord struct {
+	key CacheKey
+	at  time.Time
+}
+
+func newEvictionHistory(window time.Duration, maxSize int) *evictionHistory {
+	history := &evictionHistory{}
+	history.window = window
+	history.maxSize = maxSize
+	history.list = list.New()
+	history.keyNode = make(map[CacheKey]*list.Element)
+	return history
+}
+
+func (h *evictionHistory) add(key CacheKey, now time.Time) {
+	if node, ok := h.keyNode[key]; ok {
+		h.list.Remove(node)
+	}
+	h.keyNode[key] = h.list.PushFront(evictionRecord{key, now})
+	h.prune(now)
+}
+
+// forget drops key from the history and reports whether it was evicted within the window
+func (h *evictionHistory) forget(key CacheKey, now time.Time) bool {
+	h.prune(now)
+	node, ok := h.keyNode[key]
+	if !ok {
+		return false
+	}
+	h.list.Remove(node)
+	delete(h.keyNode, key)
+	return true
+}
+
+func (h *evictionHistory) prune(now time.Time) {
+	for h.list.Len() > 0 {
+		element := h.list.Back()
+		record := element.Value.(evictionRecord)
+		if h.list.Len() <= h.maxSize && now.Sub(record.at) < h.window {
+			return
+		}
+		h.list.Remove(element)
+		delete(h.keyNode, record.key)
+	}
+}
+
+const (
+	hitWindowStep    = 10 * time.Second
+	hitWindowBuckets = 360 // an hour of steps
+)
+
+// hitWindow counts hits and misses in a ring of buckets, one per step of time
+type hitWindow struct {
+	hits   [hitWindowBuckets]int
+	misses [hitWindowBuckets]int
+	steps  [hitWindowBuckets]int64 // the step each bucket currently counts
+}
+
+func (w *hitWindow) record(now time.Time, hit bool) {
+	step := now.UnixNano() / int64(hitWindowStep)
+	i := step % hitWindowBuckets
+	if w.steps[i] != step {
+		w.steps[i] = step
+		w.hits[i] = 0
+		w.misses[i] = 0
+	}
+	if hit {
+		w.hits[i]++
+	} else {
+		w.misses[i]++
+	}
+}
+
+func (w *hitWindow) sum(now time.Time, window time.Duration) (hits int, misses int) {
+	step := now.UnixNano() / int64(hitWindowStep)
+	steps := int64((window + hitWindowStep - 1) / hitWindowStep)
+	if steps > hitWindowBuckets {
+		steps = hitWindowBuckets
+	}
+	for s := step - steps + 1; s <= step; s++ {
+		i := s % hitWindowBuckets
+		if w.steps[i] == s {
+			hits += w.hits[i]
+			misses += w.misses[i]
+		}
+	}
+	return hits, misses
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	cache := NewCache(2, FIFO)
+	test(t, cache, [][]interface{}{
+		{"Put", "1", "1"},
+		{"Put", "2", "2"},
+		{"Get", "1", "1"},
+		{"Put", "3", "3"}, // 1 is evicted
+		{"Get", "1", nil},
+		{"Get", "3", "3"},
+	})
+
+	expected := Stats{Hits: 2, Misses: 1, Evictions: 1}
+	if cache.Stats() != expected {
+		t.Errorf("stats should be %v, but got %v", expected, cache.Stats())
+	}
+}
+
+func TestPrematureEvictions(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(2, FIFO)
+	cache.now = func() time.Time { return now }
+	cache.SetPrematureEvictionWindow(time.Minute)
+
+	test(t, cache, [][]interface{}{
+		{"Put", "1", "1"},
+		{"Put", "2", "2"},
+		{"Put", "3", "3"}, // 1 is evicted
+		{"Put", "4", "4"}, // 2 is evicted
+		{"Get", "1", nil}, // premature
+		{"Get", "1", nil}, // already counted
+	})
+	now = now.Add(time.Minute)
+	cache.Get("2") // outside the window
+
+	if cache.Stats().PrematureEvictions != 1 {
+		t.Errorf("premature evictions should be 1, but got %d", cache.Stats().PrematureEvictions)
+	}
+}
+
+func TestHitRatio(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(2, LRU)
+	cache.now = func() time.Time { return now }
+	cache.Put("1", "1")
+
+	for i := 0; i < 9; i++ {
+		cache.Get("1")
+	}
+	cache.Get("2")
+	now = now.Add(2 * time.Minute)
+	cache.Get("2")
+	cache.Get("1")
+
+	if ratio := cache.HitRatio(time.Minute); ratio != 0.5 {
+		t.Errorf("hit ratio over 1m should be 0.5, but got %f", ratio)
+	}
+	if ratio := cache.HitRatio(5 * time.Minute); ratio != 10.0/12 {
+		t.Errorf("hit ratio over 5m should be %f, but got %f", 10.0/12, ratio)
+	}
+	now = now.Add(2 * time.Hour)
+	if ratio := cache.HitRatio(time.Hour); ratio != 0 {
+		t.Errorf("hit ratio should be 0 without requests, but got %f", ratio)
+	}
+}
+
+func TestOneHitWonders(t *testing.T) {
+	cache := NewCache(2, FIFO)
+	test(t, cache, [][]interface{}{
+		{"Put", "1", "1"},
+		{"Put", "2", "2"},
+		{"Get", "1", "1"},
+		{"Put", "3", "3"}, // 1 is evicted after a hit
+		{"Put", "4", "4"}, // 2 is evicted without a hit
+		{"Put", "5", "5"}, // 3 is evicted without a hit
+		{"Get", "1", nil},
+	})
+
+	stats := cache.Stats()
+	if stats.OneHitWonders != 2 || stats.OneHitWonderRatio() != 2.0/3 {
+		t.Errorf("2 out of 3 evictions should be one-hit wonders, but got %v", stats)
+	}
+}
+
+func TestSourceStats(t *testing.T) {
+	cache := NewCache(3, FIFO)
+	cache.PutWithSource("1", "1", "warmup")
+	cache.PutWithSource("2", "2", "warmup")
+	cache.PutWithSource("3", "3", "loader")
+	cache.Get("3")
+	cache.Get("3")
+	cache.Put("4", "4") // 1 is evicted
+
+	expected := map[string]SourceStats{
+		"warmup": {Entries: 1, Evictions: 1},
+		"loader": {Entries: 1, Hits: 2},
+		"":       {Entries: 1},
+	}
+	if !reflect.DeepEqual(cache.SourceStats(), expected) {
+		t.Errorf("source stats should be %v, but got %v", expected, cache.SourceStats())
+	}
+}
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// StatsSink receives counter increments from a StatsPusher
+type StatsSink interface {
+	Count(name string, delta int64) error
+}
+
+// UDPStatsSink sends counters over UDP in the StatsD line protocol, which the Datadog agent
+// accepts as well
+type UDPStatsSink struct {
+	conn net.Conn
+}
+
+func NewUDPStatsSink(addr string) (*UDPStatsSink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &UDPStatsSink{conn: conn}, nil
+}
+
+func (s *UDPStatsSink) Count(name string, delta int64) error {
+	_, err := fmt.Fprintf(s.conn, "%s:%d|c", name, delta)
+	return err
+}
+
+func (s *UDPStatsSink) Close() error {
+	return s.conn.Close()
+}
+
+// StatsPusher periodically pushes the increase of every Stats counter to a sink. stats is
+// usually Cache.Stats or ShardedCache.Stats, both are safe to call from the pusher
+// goroutine. A counter whose push failed is pushed again with the next increase
+type StatsPusher struct {
+	stats  func() Stats
+	sink   StatsSink
+	prefix string
+	pushed map[string]int
+	failed int64
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// NewStatsPusher starts pushing the counters every interval, which must be positive
+func NewStatsPusher(stats func() Stats, sink StatsSink, prefix string,
+	interval time.Duration) (*StatsPusher, error) {
+	if interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+	p := &StatsPusher{}
+	p.stats = stats
+	p.sink = sink
+	p.prefix = prefix
+	p.pushed = make(map[string]int)
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+	go p.run(interval)
+	return p, nil
+}
+
+func (p *StatsPusher) run(interval time.Duration) {
+	defer close(p.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.push()
+		case <-p.stop:
+			p.push()
+			return
+		}
+	}
+}
+
+func (p *StatsPusher) push() {
+	stats := p.stats()
+	counters := map[string]int{
+		"hits":                stats.Hits,
+		"misses":              stats.Misses,
+		"evictions":           stats.Evictions,
+		"premature_evictions": stats.PrematureEvictions,
+		"parent_hits":         stats.ParentHits,
+		"recovered_panics":    stats.RecoveredPanics,
+		"dropped_callbacks":   stats.DroppedCallbacks,
+		"one_hit_wonders":     stats.OneHitWonders,
+		"rejected":            stats.Rejected,
+		"warmup_bypassed":     stats.WarmupBypassed,
+	}
+	for name, value := range counters {
+		delta := value - p.pushed[name]
+		if delta == 0 {
+			continue
+		}
+		if err := p.sink.Count(p.prefix+name, int64(delta)); err != nil {
+			atomic.AddInt64(&p.failed, 1)
+			continue
+		}
+		p.pushed[name] = value
+	}
+}
+
+// Failed returns the number of counter pushes the sink returned an error for
+func (p *StatsPusher) Failed() int64 {
+	return atomic.LoadInt64(&p.failed)
+}
+
+// Close pushes the counters one last time and stops the pusher, it doesn't close the sink
+func (p *StatsPusher) Close() {
+	close(p.stop)
+	<-p.done
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingSink struct {
+	mu     sync.Mutex
+	counts map[string]int64
+	fail   bool
+}
+
+func (s *recordingSink) Count(name string, delta int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return errors.New("sink down")
+	}
+	s.counts[name] += delta
+	return nil
+}
+
+func TestStatsPusher(t *testing.T) {
+	cache := NewCache(1, LRU)
+	sink := &recordingSink{counts: make(map[string]int64), fail: true}
+	pusher, err := NewStatsPusher(cache.Stats, sink, "cache.", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Put("1", "1")
+	cache.Get("1")
+	cache.Get("2")
+	pusher.push()
+	if pusher.Failed() != 2 {
+		t.Errorf("failed = %d, but want 2", pusher.Failed())
+	}
+
+	sink.fail = false
+	cache.Get("1")
+	cache.Put("2", "2")
+	pusher.Close()
+	want := map[string]int64{"cache.hits": 2, "cache.misses": 1, "cache.evictions": 1}
+	for name, count := range want {
+		if sink.counts[name] != count {
+			t.Errorf("%s = %d, but want %d", name, sink.counts[name], count)
+		}
+	}
+}
+
+func TestUDPStatsSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+	sink, err := NewUDPStatsSink(conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	if err := sink.Count("cache.hits", 3); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf[:n]); got != "cache.hits:3|c" {
+		t.Errorf("packet = %q, but want %q", got, "cache.hits:3|c")
+	}
+}
+
+func TestNewStatsPusherInvalid(t *testing.T) {
+	sink := &recordingSink{counts: make(map[string]int64)}
+	if _, err := NewStatsPusher(NewCache(1, LRU).Stats, sink, "cache.", 0); err == nil {
+		t.Errorf("interval = 0 should fail")
+	}
+}
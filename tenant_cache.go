@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// TenantCache shares a fixed capacity between tenants, each tenant gets its own cache and quota.
+// Rebalance moves capacity towards the tenants earning the most weighted hits
+type TenantCache struct {
+	capacity int
+	policy   PolicyType
+	tenants  map[string]*tenant
+}
+
+type tenant struct {
+	cache  *Cache
+	weight float64
+	hits   int // hits at the last rebalance
+}
+
+// TenantStats reports the quota and counters of a tenant
+type TenantStats struct {
+	Quota int
+	Stats
+}
+
+func NewTenantCache(capacity int, policy PolicyType) *TenantCache {
+	cache := &TenantCache{}
+	cache.capacity = capacity
+	cache.policy = policy
+	cache.tenants = make(map[string]*tenant)
+	return cache
+}
+
+// AddTenant registers a tenant with a fairness weight and splits the capacity evenly by weight.
+// The weight must be positive and finite, and the name not already registered
+func (c *TenantCache) AddTenant(name string, weight float64) error {
+	if !(weight > 0) || math.IsInf(weight, 1) {
+		return errors.New("weight must be positive and finite")
+	}
+	if _, ok := c.tenants[name]; ok {
+		return errors.New("tenant already exists")
+	}
+	c.tenants[name] = &tenant{cache: NewCache(0, c.policy), weight: weight}
+	c.assignQuotas(func(t *tenant) float64 { return t.weight })
+	return nil
+}
+
+func (c *TenantCache) Put(name string, key CacheKey, value string) error {
+	t, ok := c.tenants[name]
+	if !ok {
+		return errors.New("tenant not found")
+	}
+	t.cache.Put(key, value)
+	return nil
+}
+
+func (c *TenantCache) Get(name string, key CacheKey) (*string, error) {
+	t, ok := c.tenants[name]
+	if !ok {
+		return nil, errors.New("tenant not found")
+	}
+	return t.cache.Get(key)
+}
+
+func (c *TenantCache) Stats(name string) (TenantStats, error) {
+	t, ok := c.tenants[name]
+	if !ok {
+		return TenantStats{}, errors.New("tenant not found")
+	}
+	return TenantStats{t.cache.maxSize, t.cache.Stats()}, nil
+}
+
+// Rebalance splits the capacity in proportion to the weighted hits of each tenant since the
+// previous rebalance, every tenant keeps a floor so it can earn hits back
+func (c *TenantCache) Rebalance() {
+	utility := make(map[*tenant]float64, len(c.tenants))
+	total := 0.0
+	for _, t := range c.tenants {
+		hits := t.cache.Stats().Hits
+		utility[t] = t.weight * float64(hits-t.hits)
+		total += utility[t]
+		t.hits = hits
+	}
+	if total == 0 {
+		c.assignQuotas(func(t *tenant) float64 { return t.weight })
+		return
+	}
+	c.assignQuotas(func(t *tenant) float64 { return utility[t] })
+}
+
+// assignQuotas gives each tenant a floor of a quarter of its even share and at least one entry,
+// the rest of the capacity is split by share and the rounding remainder goes to the tenants with
+// the largest share. With fewer entries than tenants the quotas add up to more than the capacity
+func (c *TenantCache) assignQuotas(share func(*tenant) float64) {
+	if len(c.tenants) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(c.tenants))
+	total := 0.0
+	for name, t := range c.tenants {
+		names = append(names, name)
+		total += share(t)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		si, sj := share(c.tenants[names[i]]), share(c.tenants[names[j]])
+		return si > sj || si == sj && names[i] < names[j]
+	})
+
+	floor := c.capacity / (4 * len(c.tenants))
+	if floor < 1 {
+		floor = 1 // a tenant without quota could never earn hits back
+	}
+	spare := c.capacity - floor*len(c.tenants)
+	if spare < 0 {
+		spare = 0
+	}
+	quotas := make([]int, len(names))
+	assigned := 0
+	for i, name := range names {
+		quotas[i] = floor
+		if total > 0 {
+			quotas[i] += int(float64(spare) * share(c.tenants[name]) / total)
+		}
+		assigned += quotas[i]
+	}
+	for i := 0; assigned < c.capacity; i = (i + 1) % len(names) {
+		quotas[i]++
+		assigned++
+	}
+
+	for i, name := range names {
+		c.tenants[name].cache.Resize(quotas[i])
+	}
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestTenantCache(t *testing.T) {
+	cache := NewTenantCache(100, LRU)
+	cache.AddTenant("a", 1)
+	cache.AddTenant("b", 1)
+
+	for _, name := range []string{"a", "b"} {
+		stats, _ := cache.Stats(name)
+		if stats.Quota != 50 {
+			t.Errorf("tenant = %s, quota should be 50, but got %d", name, stats.Quota)
+		}
+	}
+
+	for i := 0; i < 40; i++ {
+		key := CacheKey(fmt.Sprint(i))
+		cache.Put("a", key, "a")
+		cache.Put("b", key, "b")
+		cache.Get("a", key)
+		cache.Get("a", key)
+		cache.Get("a", key)
+		cache.Get("b", key)
+	}
+	cache.Rebalance()
+
+	a, _ := cache.Stats("a")
+	b, _ := cache.Stats("b")
+	if a.Quota+b.Quota != 100 || a.Quota <= b.Quota || b.Quota < 12 {
+		t.Errorf("tenant a should earn more capacity, but got a = %d, b = %d", a.Quota, b.Quota)
+	}
+	if b.Stats.Evictions == 0 {
+		t.Errorf("tenant b should evict entries when its quota shrinks")
+	}
+	if _, err := cache.Get("c", "1"); err == nil {
+		t.Errorf("unknown tenant should fail")
+	}
+}
+
+func TestTenantCacheSmallCapacity(t *testing.T) {
+	cache := NewTenantCache(2, LRU)
+	for _, name := range []string{"a", "b", "c"} {
+		cache.AddTenant(name, 1)
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		stats, _ := cache.Stats(name)
+		if stats.Quota < 1 {
+			t.Errorf("tenant = %s, quota should be at least 1, but got %d", name, stats.Quota)
+		}
+		cache.Put(name, "1", name)
+		cache.Put(name, "2", name)
+	}
+
+	empty := NewCache(0, LRU)
+	empty.Put("1", "1")
+	if empty.size != 0 {
+		t.Errorf("put on a cache without capacity should be a no-op, but size = %d", empty.size)
+	}
+}
+
+func TestTenantCacheShrinkLFU(t *testing.T) {
+	cache := NewTenantCache(100, LFU)
+	cache.AddTenant("a", 1)
+	cache.AddTenant("b", 1)
+	for i := 0; i < 50; i++ {
+		key := CacheKey(fmt.Sprint(i))
+		cache.Put("a", key, "a")
+		cache.Put("b", key, "b")
+		cache.Get("a", key)
+		cache.Get("a", key)
+	}
+	// shrinking b evicts many LFU entries in a row
+	cache.Rebalance()
+
+	b, _ := cache.Stats("b")
+	if b.Quota >= 50 || b.Stats.Evictions != 50-b.Quota {
+		t.Errorf("tenant b should shrink to its quota, but got quota = %d, evictions = %d", b.Quota, b.Stats.Evictions)
+	}
+}
+
+func TestAddTenantInvalid(t *testing.T) {
+	cache := NewTenantCache(10, LRU)
+	for _, weight := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		if err := cache.AddTenant("a", weight); err == nil {
+			t.Errorf("weight = %v should be rejected", weight)
+		}
+	}
+	if err := cache.AddTenant("a", 1); err != nil {
+		t.Errorf("weight = 1 should be accepted, but got %v", err)
+	}
+	cache.Put("a", "1", "1")
+	if err := cache.AddTenant("a", 2); err == nil {
+		t.Errorf("a duplicate tenant should be rejected")
+	}
+	if value, err := cache.Get("a", "1"); err != nil || *value != "1" {
+		t.Errorf("a rejected duplicate should keep the tenant cache")
+	}
+}
@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// maxReplayedAccesses bounds the accesses replayed on the receiving side for a single entry
+const maxReplayedAccesses = 16
+
+// maxTransferredLength bounds the length of a key or value read by ReceiveFrom, so a corrupted
+// stream can't make it allocate arbitrary amounts of memory
+const maxTransferredLength = 1 << 24
+
+// TransferTo streams the resident entries to w, typically a connection to the instance replacing
+// this one during a deploy. The stream starts with a format version header, then each entry is
+// written as length-prefixed key and value followed by its access count, zero when the policy
+// doesn't track frequencies. Entries are written hottest first in the policy order, so an
+// interrupted transfer still hands over the hot set
+func (c *Cache) TransferTo(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	buf := make([]byte, binary.MaxVarintLen64)
+	writeUvarint := func(x uint64) error {
+		_, err := writer.Write(buf[:binary.PutUvarint(buf, x)])
+		return err
+	}
+
+	if err := writeTransferHeader(writer); err != nil {
+		return err
+	}
+	snapshot := c.Snapshot()
+	for _, key := range c.hottestFirst(snapshot) {
+		if _, ok := c.tombstones[key]; ok {
+			continue
+		}
+		value := snapshot[key]
+		count, _ := c.AccessCount(key)
+		if err := writeUvarint(uint64(len(key))); err != nil {
+			return err
+		}
+		if _, err := writer.WriteString(string(key)); err != nil {
+			return err
+		}
+		if err := writeUvarint(uint64(len(value))); err != nil {
+			return err
+		}
+		if _, err := writer.WriteString(value); err != nil {
+			return err
+		}
+		if err := writeUvarint(uint64(count)); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// ReceiveFrom reads entries written by TransferTo until EOF and puts them in the cache, replaying
+// part of their accesses so frequency based policies keep the hot set. Streams written by older
+// releases are migrated, a *FormatVersionError is returned when that isn't possible. It returns
+// the number of entries received
+func (c *Cache) ReceiveFrom(r io.Reader) (int, error) {
+	reader := bufio.NewReader(r)
+	version, err := readTransferHeader(reader)
+	if err != nil {
+		return 0, err
+	}
+	readString := func() (string, error) {
+		length, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return "", err
+		}
+		if length > maxTransferredLength {
+			return "", errors.New("transferred entry too large")
+		}
+		buf := make([]byte, length)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return "", err
+		}
+		return string(buf), nil
+	}
+
+	received := 0
+	for {
+		key, err := readString()
+		if err == io.EOF {
+			c.logger.Info("transfer received", "entries", received)
+			return received, nil
+		}
+		if err != nil {
+			return received, err
+		}
+		value, err := readString()
+		if err != nil {
+			return received, truncated(err)
+		}
+		count, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return received, truncated(err)
+		}
+
+		entry := ndjsonEntry{CacheKey(key), value, int(count)}
+		if err := migrate(&entry, version); err != nil {
+			return received, err
+		}
+		c.restore(entry.Key, entry.Value, entry.AccessCount)
+		received++
+	}
+}
+
+// hottestFirst orders the keys of the snapshot from the last victim to the next one, keys the
+// policy doesn't walk (pinned keys, or every key when it has no candidates) come first
+func (c *Cache) hottestFirst(snapshot Snapshot) []CacheKey {
+	walked := make([]CacheKey, 0, len(snapshot))
+	if policy, ok := candidatePolicy(c.policy); ok {
+		policy.Candidates(func(key CacheKey) bool {
+			if _, ok := snapshot[key]; ok {
+				walked = append(walked, key)
+			}
+			return true
+		})
+	}
+
+	seen := make(map[CacheKey]bool, len(walked))
+	keys := make([]CacheKey, 0, len(snapshot))
+	for _, key := range walked {
+		seen[key] = true
+	}
+	for key := range snapshot {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	for i := len(walked) - 1; i >= 0; i-- {
+		keys = append(keys, walked[i])
+	}
+	return keys
+}
+
+// restore puts an entry received from another cache and replays part of its accesses
+func (c *Cache) restore(key CacheKey, value string, count int) {
+	key = c.canonical(key)
+	if c.doorkeeper != nil {
+		// entries handed over are known to be requested, skip the warm-up
+		for c.doorkeeper.estimate(key) < 2 {
+			c.doorkeeper.increment(key)
+		}
+	}
+	c.put(key, value, "", 0)
+	if _, ok := c.data[key]; !ok {
+		return // not admitted
+	}
+	for i := 1; i < count && i <= maxReplayedAccesses; i++ {
+		c.policy.Access(key)
+	}
+}
+
+func truncated(err error) error {
+	if err == io.EOF {
+		return errors.New("truncated entry")
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"bytes"
+	"net"
+	"testing"
+)
+
+func TestTransfer(t *testing.T) {
+	source := NewCache(3, LFU)
+	source.Put("1", "1")
+	source.Put("2", "2")
+	source.Put("3", "3")
+	source.Get("1")
+	source.Get("1")
+	source.Get("3")
+
+	client, server := net.Pipe()
+	go func() {
+		source.TransferTo(client)
+		client.Close()
+	}()
+
+	target := NewCache(3, LFU)
+	received, err := target.ReceiveFrom(server)
+	if err != nil || received != 3 {
+		t.Fatalf("3 entries should be received, but got %d, %v", received, err)
+	}
+	if count, _ := target.AccessCount("1"); count != 3 {
+		t.Errorf("access count of key = 1 should be 3, but got %d", count)
+	}
+
+	test(t, target, [][]interface{}{
+		{"Put", "4", "4"}, // 2 is the coldest and evicted
+		{"Get", "2", nil},
+		{"Get", "1", "1"},
+		{"Get", "3", "3"},
+	})
+}
+
+func TestReceiveFromTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	source := NewCache(1, LRU)
+	source.Put("1", "1")
+	source.TransferTo(&buf)
+
+	data := buf.Bytes()
+	if _, err := NewCache(1, LRU).ReceiveFrom(bytes.NewReader(data[:len(data)-1])); err == nil {
+		t.Errorf("truncated stream should fail")
+	}
+}
+
+func TestTransferHottestFirst(t *testing.T) {
+	var buf bytes.Buffer
+	source := NewCache(3, LFU)
+	source.Put("1", "1")
+	source.Put("2", "2")
+	source.Put("3", "3")
+	source.Get("2")
+	source.Get("2")
+	source.Get("3")
+	source.TransferTo(&buf)
+
+	// only the header and the first entry written, key = 2 with 3 accesses, reach the receiver
+	target := NewCache(1, LFU)
+	data := buf.Bytes()
+	target.ReceiveFrom(bytes.NewReader(data[:len(transferMagic)+6]))
+	if value, _ := target.Get("2"); value == nil || *value != "2" {
+		t.Errorf("the hottest entry should be transferred first")
+	}
+}
+
+func TestReceiveFromTooLarge(t *testing.T) {
+	// a key length of 2^62 followed by nothing
+	data := []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x40}
+	if _, err := NewCache(1, LRU).ReceiveFrom(bytes.NewReader(data)); err == nil {
+		t.Errorf("oversized entry should fail")
+	}
+}
@@ -0,0 +1,321 @@
+package cache
+
+import (
+	"container/heap"
+	"container/list"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// maxVictimRetries bounds the protected candidates skipped while electing a victim
+const maxVictimRetries = 16
+
+// SetStickyWindow makes newly inserted entries immune from eviction for a duration and/or a
+// number of Put and Get calls, so that a burst of inserts can't evict each other before they get
+// a chance to be accessed. Zero disables either bound
+func (c *Cache) SetStickyWindow(duration time.Duration, ops int) {
+	c.stickyFor = duration
+	c.stickyOps = uint64(ops)
+}
+
+// VictimFilter is consulted for every eviction candidate, returning false vetoes the candidate
+// and the policy offers the next best one
+type VictimFilter func(key CacheKey) bool
+
+// SetVictimFilter registers filter. A vetoed key is never evicted: when no other candidate is
+// found within maxVictimRetries a new key is not stored and counted as rejected, and Resize or
+// EvictN stop short of their target
+func (c *Cache) SetVictimFilter(filter VictimFilter) {
+	c.victimFilter = filter
+}
+
+// TryPut stores key like Put, except that when the cache is full and every eviction candidate is
+// protected it returns false instead of evicting one of them, letting the caller bypass the cache.
+// It also returns false when the admission policy turns key down or the cache has no capacity
+func (c *Cache) TryPut(key CacheKey, value string) bool {
+	key = c.canonical(key)
+	c.purgeTombstones()
+	if _, ok := c.data[key]; !ok && !c.admitted(key) {
+		return false
+	}
+	if _, ok := c.data[key]; !ok && c.size >= c.maxSize && c.size > 0 {
+		victimKey, ok := c.electVictim(false, c.admitter(key))
+		if !ok {
+			atomic.AddInt64(&c.stats.rejected, 1)
+			return false
+		}
+		c.evictKey(victimKey)
+	}
+	return c.put(key, value, "", 0)
+}
+
+// electVictim walks the eviction candidates until one is not protected and takes it out of the
+// policy. When every candidate is protected the first sticky one is elected if force is set, so
+// the cache stays within its capacity, otherwise no victim is elected, vetoed keys never are.
+// admit, when set, may turn the elected victim down, it then stays in the policy untouched.
+// Without protections or admission the policy elects its victim itself, CLOCK only clears
+// reference bits then
+func (c *Cache) electVictim(force bool, admit func(victim CacheKey) bool) (CacheKey, bool) {
+	policy, ok := candidatePolicy(c.policy)
+	protecting := c.victimFilter != nil || c.stickyFor > 0 || c.stickyOps > 0
+	if !ok || !protecting && admit == nil {
+		victimKey, elected := c.electVictimByRemoval(force)
+		if elected && admit != nil && !admit(victimKey) {
+			c.policy.Add(victimKey)
+			return victimKey, false
+		}
+		return victimKey, elected
+	}
+
+	var victimKey, fallback CacheKey
+	candidates, elected, sticky := 0, false, false
+	policy.Candidates(func(key CacheKey) bool {
+		candidates++
+		if !c.vetoed(key) {
+			if !c.sticky(key) {
+				victimKey, elected = key, true
+				return false
+			}
+			if !sticky {
+				fallback, sticky = key, true
+			}
+		}
+		return candidates <= maxVictimRetries
+	})
+	if !elected && force && sticky {
+		victimKey, elected = fallback, true
+	}
+	if !elected || admit != nil && !admit(victimKey) {
+		return victimKey, false
+	}
+	policy.Evict(victimKey)
+	return victimKey, true
+}
+
+// electVictimByRemoval is electVictim for policies that can only be asked for victims, skipped
+// candidates are added back to the policy afterwards and lose their position and frequency
+func (c *Cache) electVictimByRemoval(force bool) (CacheKey, bool) {
+	var skipped []CacheKey
+	var victimKey CacheKey
+	fallback, elected := -1, false // fallback is the first skipped key that is only sticky
+	for len(skipped) <= maxVictimRetries && len(skipped) < c.size {
+		key := c.policy.Victim()
+		if _, ok := c.meta[key]; !ok {
+			break // no other evictable key, e.g. every key is pinned
+		}
+		vetoed := c.vetoed(key)
+		if !vetoed && !c.sticky(key) {
+			victimKey, elected = key, true
+			break
+		}
+		if !vetoed && fallback < 0 {
+			fallback = len(skipped)
+		}
+		skipped = append(skipped, key)
+	}
+	if !elected && force && fallback >= 0 {
+		victimKey, elected = skipped[fallback], true
+		skipped = append(skipped[:fallback], skipped[fallback+1:]...)
+	}
+
+	for _, key := range skipped {
+		c.policy.Add(key)
+	}
+	return victimKey, elected
+}
+
+// vetoed reports whether the victim filter keeps key from being evicted
+func (c *Cache) vetoed(key CacheKey) bool {
+	return c.victimFilter != nil && !c.victimFilter(key)
+}
+
+// sticky reports whether key is still within its sticky window
+func (c *Cache) sticky(key CacheKey) bool {
+	meta, ok := c.meta[key]
+	if !ok {
+		return false
+	}
+	if c.stickyFor > 0 && c.now().Sub(meta.insertedAt) < c.stickyFor {
+		return true
+	}
+	if c.stickyOps > 0 && c.ops-meta.insertedOp < c.stickyOps {
+		return true
+	}
+	return false
+}
+
+// Candidates of the built-in policies
+
+func (p *FIFOPolicy) Candidates(fn func(CacheKey) bool) {
+	for element := p.list.Back(); element != nil && fn(element.Value.(CacheKey)); element = element.Prev() {
+	}
+}
+
+func (p *FIFOPolicy) Evict(key CacheKey) {
+	p.Remove(key)
+}
+
+func (p *LRUPolicy) Candidates(fn func(CacheKey) bool) {
+	for element := p.list.Back(); element != nil && fn(element.Value.(CacheKey)); element = element.Prev() {
+	}
+}
+
+func (p *LRUPolicy) Evict(key CacheKey) {
+	p.Remove(key)
+}
+
+func (p *MRUPolicy) Candidates(fn func(CacheKey) bool) {
+	for element := p.list.Front(); element != nil && fn(element.Value.(CacheKey)); element = element.Next() {
+	}
+}
+
+// Candidates offers the keys without the reference bit in the order the hand meets them, then
+// the referenced ones, which is the order a sweep of the hand would evict them in
+func (p *ClockPolicy) Candidates(fn func(CacheKey) bool) {
+	if p.clockHand == nil {
+		return
+	}
+	for _, referenced := range []bool{false, true} {
+		node := p.clockHand
+		for i := p.list.Len(); i > 0; i-- {
+			item := node.Value.(*ClockItem)
+			if item.bit == referenced && !fn(item.key) {
+				return
+			}
+			node = node.Next()
+		}
+	}
+}
+
+func (p *ClockPolicy) Evict(key CacheKey) {
+	p.Remove(key)
+}
+
+func (p *LFUPolicy) Candidates(fn func(CacheKey) bool) {
+	p.decay()
+	frequencies := make([]Frequency, 0, len(p.freqList))
+	for frequency := range p.freqList {
+		frequencies = append(frequencies, frequency)
+	}
+	sort.Slice(frequencies, func(i, j int) bool { return frequencies[i] < frequencies[j] })
+	for _, frequency := range frequencies {
+		for element := p.freqList[frequency].Back(); element != nil; element = element.Prev() {
+			if !fn(element.Value.(LFUItem).key) {
+				return
+			}
+		}
+	}
+}
+
+func (p *LFUPolicy) Evict(key CacheKey) {
+	p.Remove(key)
+}
+
+func (p *SLRUPolicy) Candidates(fn func(CacheKey) bool) {
+	for _, segment := range []*list.List{p.probation, p.protected} {
+		for element := segment.Back(); element != nil; element = element.Prev() {
+			if !fn(element.Value.(*slruItem).key) {
+				return
+			}
+		}
+	}
+}
+
+func (p *SLRUPolicy) Evict(key CacheKey) {
+	p.Remove(key)
+}
+
+// Candidates offers the resident HIR keys in queue order, then the LIR keys from the bottom of
+// the stack
+func (p *LIRSPolicy) Candidates(fn func(CacheKey) bool) {
+	for element := p.hirQueue.Back(); element != nil; element = element.Prev() {
+		if !fn(element.Value.(*lirsEntry).key) {
+			return
+		}
+	}
+	for element := p.stack.Back(); element != nil; element = element.Prev() {
+		if entry := element.Value.(*lirsEntry); entry.state == lirsLIR && !fn(entry.key) {
+			return
+		}
+	}
+}
+
+// Candidates offers the sampled keys Victim would compare by increasing frequency, then the
+// remaining keys from the least recently used
+func (p *SketchLFUPolicy) Candidates(fn func(CacheKey) bool) {
+	var sampled []CacheKey
+	element := p.list.Back()
+	for ; element != nil && len(sampled) < sketchLFUSamples; element = element.Prev() {
+		sampled = append(sampled, element.Value.(CacheKey))
+	}
+	sort.SliceStable(sampled, func(i, j int) bool {
+		return p.sketch.estimate(sampled[i]) < p.sketch.estimate(sampled[j])
+	})
+	for _, key := range sampled {
+		if !fn(key) {
+			return
+		}
+	}
+	for ; element != nil && fn(element.Value.(CacheKey)); element = element.Prev() {
+	}
+}
+
+func (p *SketchLFUPolicy) Evict(key CacheKey) {
+	p.Remove(key)
+}
+
+// Candidates offers the keys by increasing priority, walking the heap without popping it
+func (p *GDSFPolicy) Candidates(fn func(CacheKey) bool) {
+	if len(p.heap) == 0 {
+		return
+	}
+	frontier := &gdsfFrontier{heap: p.heap, indexes: []int{0}}
+	for frontier.Len() > 0 {
+		index := heap.Pop(frontier).(int)
+		if !fn(p.heap[index].key) {
+			return
+		}
+		for _, child := range []int{2*index + 1, 2*index + 2} {
+			if child < len(p.heap) {
+				heap.Push(frontier, child)
+			}
+		}
+	}
+}
+
+// gdsfFrontier is a min-heap of positions in a gdsfHeap, the children of a position are only
+// pushed once it is popped
+type gdsfFrontier struct {
+	heap    gdsfHeap
+	indexes []int
+}
+
+func (f *gdsfFrontier) Len() int { return len(f.indexes) }
+func (f *gdsfFrontier) Less(i, j int) bool {
+	return f.heap[f.indexes[i]].priority < f.heap[f.indexes[j]].priority
+}
+func (f *gdsfFrontier) Swap(i, j int)      { f.indexes[i], f.indexes[j] = f.indexes[j], f.indexes[i] }
+func (f *gdsfFrontier) Push(x interface{}) { f.indexes = append(f.indexes, x.(int)) }
+func (f *gdsfFrontier) Pop() interface{} {
+	index := f.indexes[len(f.indexes)-1]
+	f.indexes = f.indexes[:len(f.indexes)-1]
+	return index
+}
+
+// Candidates offers the keys from a random position on
+func (p *RandomPolicy) Candidates(fn func(CacheKey) bool) {
+	if len(p.keys) == 0 {
+		return
+	}
+	start := p.rand.Intn(len(p.keys))
+	for i := range p.keys {
+		if !fn(p.keys[(start+i)%len(p.keys)]) {
+			return
+		}
+	}
+}
+
+func (p *RandomPolicy) Evict(key CacheKey) {
+	p.Remove(key)
+}
@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStickyWindowOps(t *testing.T) {
+	cache := NewCache(3, FIFO)
+	cache.SetStickyWindow(0, 3)
+	test(t, cache, [][]interface{}{
+		{"Put", "1", "1"},
+		{"Get", "1", "1"},
+		{"Get", "1", "1"},
+		{"Put", "2", "2"},
+		{"Put", "3", "3"},
+		{"Put", "4", "4"}, // 2 and 3 are sticky, 1 is not
+		{"Get", "1", nil},
+		{"Put", "5", "5"}, // 2 is no longer sticky
+		{"Get", "2", nil},
+		{"Get", "3", "3"},
+	})
+}
+
+func TestStickyWindowDuration(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(2, LRU)
+	cache.now = func() time.Time { return now }
+	cache.SetStickyWindow(time.Minute, 0)
+
+	cache.Put("1", "1")
+	now = now.Add(time.Minute)
+	cache.Put("2", "2")
+	cache.Get("1")
+	cache.Put("3", "3") // 2 is sticky, 1 is evicted despite being recently used
+	test(t, cache, [][]interface{}{
+		{"Get", "1", nil},
+		{"Get", "2", "2"},
+	})
+
+	cache.Put("4", "4") // everything is sticky, the policy victim is evicted anyway
+	if cache.size != 2 {
+		t.Errorf("size should be 2, but got %d", cache.size)
+	}
+}
+
+func TestCandidatesMatchVictim(t *testing.T) {
+	for _, policyType := range []PolicyType{FIFO, LRU, MRU, LFU, CLOCK, SLRU, LIRS, GDSF} {
+		policies := [2]CachePolicy{GetCachePolicy(policyType), GetCachePolicy(policyType)}
+		for _, policy := range policies {
+			for _, key := range []CacheKey{"1", "2", "3", "4", "5"} {
+				policy.Add(key)
+			}
+			policy.Access("1")
+			policy.Access("3")
+			policy.Access("3")
+		}
+		rounds := 5
+		if policyType == CLOCK {
+			rounds = 1 // Victim clears the reference bits it passes, Candidates doesn't
+		}
+		for i := 0; i < rounds; i++ {
+			var first CacheKey
+			policies[0].(CandidatePolicy).Candidates(func(key CacheKey) bool {
+				first = key
+				return false
+			})
+			policies[0].(CandidatePolicy).Evict(first)
+			if victim := policies[1].Victim(); victim != first {
+				t.Errorf("policy = %v, first candidate = %s, but Victim = %s", policyType, first, victim)
+				break
+			}
+		}
+	}
+}
+
+func TestStickyWindowKeepsPolicyState(t *testing.T) {
+	cache := NewCache(2, LFU)
+	cache.SetStickyWindow(0, 3)
+	cache.Put("2", "2")
+	for i := 0; i < 3; i++ {
+		cache.Get("2")
+	}
+	cache.Put("1", "1")
+	cache.Get("1")
+	cache.Put("3", "3") // 1 is sticky, 2 is evicted
+	if count, _ := cache.AccessCount("1"); count != 2 {
+		t.Errorf("access count of key = 1 should stay 2, but got %d", count)
+	}
+	test(t, cache, [][]interface{}{
+		{"Get", "2", nil},
+	})
+}
+
+func TestVictimFilter(t *testing.T) {
+	dirty := map[CacheKey]bool{"1": true, "2": true}
+	cache := NewCache(3, LRU)
+	cache.SetVictimFilter(func(key CacheKey) bool { return !dirty[key] })
+
+	test(t, cache, [][]interface{}{
+		{"Put", "1", "1"},
+		{"Put", "2", "2"},
+		{"Put", "3", "3"},
+		{"Put", "4", "4"}, // 1 and 2 are vetoed
+		{"Get", "3", nil},
+		{"Get", "1", "1"},
+		{"Get", "2", "2"},
+	})
+
+	dirty["1"] = false
+	cache.Put("5", "5") // 4 is now least recently used
+	test(t, cache, [][]interface{}{
+		{"Get", "4", nil},
+		{"Get", "1", "1"},
+	})
+}
+
+func TestVictimFilterRejects(t *testing.T) {
+	for _, policy := range []CachePolicy{NewLRUPolicy(), victimOnlyPolicy{NewLRUPolicy()}} {
+		cache := NewCacheWithPolicy(2, policy)
+		cache.SetVictimFilter(func(key CacheKey) bool { return key != "1" && key != "2" })
+		cache.Put("1", "1")
+		cache.Put("2", "2")
+		cache.Put("3", "3") // every key is vetoed, 3 is not stored
+		test(t, cache, [][]interface{}{
+			{"Get", "1", "1"},
+			{"Get", "2", "2"},
+			{"Get", "3", nil},
+		})
+		if cache.Stats().Rejected != 1 {
+			t.Errorf("policy = %T, rejected should be 1, but got %d", policy, cache.Stats().Rejected)
+		}
+	}
+}
+
+func TestTryPut(t *testing.T) {
+	dirty := map[CacheKey]bool{"1": true, "2": true}
+	cache := NewCache(2, FIFO)
+	cache.SetVictimFilter(func(key CacheKey) bool { return !dirty[key] })
+	cache.Put("1", "1")
+	cache.Put("2", "2")
+
+	if cache.TryPut("3", "3") {
+		t.Errorf("key = 3 should be rejected while every entry is protected")
+	}
+	if !cache.TryPut("1", "one") {
+		t.Errorf("updating a resident key should never be rejected")
+	}
+	dirty["2"] = false
+	if !cache.TryPut("3", "3") {
+		t.Errorf("key = 3 should be admitted once 2 can be evicted")
+	}
+	test(t, cache, [][]interface{}{
+		{"Get", "1", "one"},
+		{"Get", "2", nil},
+		{"Get", "3", "3"},
+	})
+	if cache.Stats().Rejected != 1 {
+		t.Errorf("rejected should be 1, but got %d", cache.Stats().Rejected)
+	}
+	if NewCache(0, FIFO).TryPut("1", "1") {
+		t.Errorf("a cache without capacity should not report storing key = 1")
+	}
+}
+
+func TestVictimFilterKeepsPolicyState(t *testing.T) {
+	dirty := map[CacheKey]bool{"1": true}
+	cache := NewCache(3, LRU)
+	cache.SetVictimFilter(func(key CacheKey) bool { return !dirty[key] })
+	test(t, cache, [][]interface{}{
+		{"Put", "1", "1"},
+		{"Put", "2", "2"},
+		{"Put", "3", "3"},
+		{"Put", "4", "4"}, // 1 is vetoed, 2 is evicted
+	})
+	delete(dirty, "1")
+	test(t, cache, [][]interface{}{
+		{"Put", "5", "5"}, // 1 is still the least recently used
+		{"Get", "1", nil},
+		{"Get", "3", "3"},
+	})
+
+	lfu := NewCache(2, LFU)
+	lfu.SetVictimFilter(func(key CacheKey) bool { return key != "1" })
+	lfu.Put("1", "1")
+	for i := 0; i < 5; i++ {
+		lfu.Get("1")
+	}
+	lfu.Put("2", "2")
+	lfu.Put("3", "3") // 1 is vetoed, 2 is evicted
+	if count, _ := lfu.AccessCount("1"); count != 6 {
+		t.Errorf("access count of key = 1 should stay 6, but got %d", count)
+	}
+
+	gdsf := NewCache(2, GDSF)
+	gdsf.SetStickyWindow(0, 2)
+	gdsf.Put("2", "2")
+	gdsf.PutWithCost("1", string(make([]byte, 1000)), 1)
+	gdsf.Put("3", "3") // 1 is sticky, 2 is evicted
+	if item := gdsf.policy.(*GDSFPolicy).keyItem["1"]; item.size != 1000 {
+		t.Errorf("size of key = 1 should stay 1000, but got %d", item.size)
+	}
+	gdsf.Put("4", "4") // 3 is sticky, 1 has the lowest priority
+	test(t, gdsf, [][]interface{}{
+		{"Get", "1", nil},
+		{"Get", "2", nil},
+		{"Get", "3", "3"},
+	})
+}
@@ -0,0 +1,138 @@
+package cache
+
+import "errors"
+
+// viewBuckets is the number of maps the content of a View is split in, a write after View only
+// copies the bucket of its key instead of the whole content
+const viewBuckets = 64
+
+// View is a consistent read-only view of the cache, it stays stable while the cache mutates so
+// multi-key reads observe a single point in time. Reads through a View don't update the policy
+type View struct {
+	buckets   []map[CacheKey]string
+	size      int
+	canonical KeyTransformer
+}
+
+// publishedView is the content handed to the next View. Once a View was taken the writes keep it
+// up to date, a bucket shared with a View is copied before its first write
+type publishedView struct {
+	buckets []map[CacheKey]string
+	shared  []bool
+	size    int
+}
+
+// View publishes the current content of the cache. Views share their buckets with the cache, a
+// write copies the bucket of its key the first time it touches it after a call. The first call
+// builds the published content from the whole cache, as does the first call after NewEpoch
+func (c *Cache) View() *View {
+	if c.view != nil {
+		return c.view
+	}
+	if c.published == nil {
+		c.publish()
+	}
+
+	published := c.published
+	view := &View{size: published.size, canonical: c.keyTransformer}
+	view.buckets = append([]map[CacheKey]string(nil), published.buckets...)
+	for i := range published.shared {
+		published.shared[i] = true
+	}
+	c.view = view
+	return view
+}
+
+func (c *Cache) publish() {
+	published := &publishedView{}
+	published.buckets = make([]map[CacheKey]string, viewBuckets)
+	published.shared = make([]bool, viewBuckets)
+	for i := range published.buckets {
+		published.buckets[i] = make(map[CacheKey]string)
+	}
+	for key, value := range c.data {
+		if _, ok := c.tombstones[key]; ok || c.meta[key].epoch < c.epoch {
+			continue
+		}
+		published.buckets[viewBucket(key)][key] = value
+		published.size++
+	}
+	c.published = published
+}
+
+// publishWrite hands the write of a visible key to the next View
+func (c *Cache) publishWrite(key CacheKey, value string) {
+	c.view = nil
+	if bucket := c.publishedBucket(key); bucket != nil {
+		if _, ok := bucket[key]; !ok {
+			c.published.size++
+		}
+		bucket[key] = value
+	}
+}
+
+// publishRemoval hides key from the next View
+func (c *Cache) publishRemoval(key CacheKey) {
+	c.view = nil
+	if bucket := c.publishedBucket(key); bucket != nil {
+		if _, ok := bucket[key]; ok {
+			delete(bucket, key)
+			c.published.size--
+		}
+	}
+}
+
+// unpublish drops the published content after a change to every key, the next View rebuilds it
+func (c *Cache) unpublish() {
+	c.view = nil
+	c.published = nil
+}
+
+// publishedBucket returns the bucket of key ready to be written, nil when nothing is published
+func (c *Cache) publishedBucket(key CacheKey) map[CacheKey]string {
+	published := c.published
+	if published == nil {
+		return nil
+	}
+	i := viewBucket(key)
+	if published.shared[i] {
+		bucket := make(map[CacheKey]string, len(published.buckets[i]))
+		for key, value := range published.buckets[i] {
+			bucket[key] = value
+		}
+		published.buckets[i] = bucket
+		published.shared[i] = false
+	}
+	return published.buckets[i]
+}
+
+func viewBucket(key CacheKey) int {
+	hash, _ := bloomHash(key)
+	return int(hash % viewBuckets)
+}
+
+func (v *View) Get(key CacheKey) (*string, error) {
+	if v.canonical != nil {
+		key = v.canonical(key)
+	}
+	if value, ok := v.buckets[viewBucket(key)][key]; ok {
+		return &value, nil
+	}
+
+	return nil, errors.New("key not found")
+}
+
+func (v *View) Len() int {
+	return v.size
+}
+
+// Range calls fn for every entry of the view until fn returns false
+func (v *View) Range(fn func(Entry) bool) {
+	for _, bucket := range v.buckets {
+		for key, value := range bucket {
+			if !fn(Entry{key, value}) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestView(t *testing.T) {
+	cache := NewCache(2, FIFO)
+	cache.Put("1", "1")
+	cache.Put("2", "2")
+	view := cache.View()
+	if cache.View() != view {
+		t.Errorf("view should be shared until the next write")
+	}
+
+	cache.Put("3", "3") // 1 is evicted
+	cache.Delete("2")
+	for _, key := range []CacheKey{"1", "2"} {
+		if value, err := view.Get(key); err != nil || *value != string(key) {
+			t.Errorf("key = %s should still be in the view", key)
+		}
+	}
+	if _, err := view.Get("3"); err == nil {
+		t.Errorf("key = 3 was written after the view")
+	}
+
+	view = cache.View()
+	if view.Len() != 1 {
+		t.Errorf("len should be 1, but got %d", view.Len())
+	}
+}
+
+func TestViewSharesBuckets(t *testing.T) {
+	cache := NewCache(1000, FIFO)
+	for i := 0; i < 1000; i++ {
+		cache.Put(CacheKey(fmt.Sprint(i)), "old")
+	}
+	first := cache.View()
+	cache.Put("0", "new")
+	cache.Delete("1")
+	cache.NewEpoch()
+	cache.Put("2", "new")
+	second := cache.View()
+	cache.Put("3", "new")
+	third := cache.View()
+
+	if value, _ := first.Get("0"); *value != "old" || first.Len() != 1000 {
+		t.Errorf("first view should not see the later writes")
+	}
+	if second.Len() != 1 || third.Len() != 2 {
+		t.Errorf("len should be 1 and 2, but got %d and %d", second.Len(), third.Len())
+	}
+	shared := 0
+	for i := range third.buckets {
+		if reflect.ValueOf(second.buckets[i]).Pointer() == reflect.ValueOf(third.buckets[i]).Pointer() {
+			shared++
+		}
+	}
+	if shared != viewBuckets-1 {
+		t.Errorf("views should only differ by the written bucket, but %d buckets are shared", shared)
+	}
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// SetWarmup enables the cold-start bypass: for duration, or until the hit ratio over the last
+// minute reaches targetHitRatio when it is positive, a new key is only admitted once it has been
+// missed at least twice. The initial flood of unique requests then can't churn the cache before it
+// has learned the working set. Misses are counted approximately in a sketch sized to the capacity
+// and aged like TinyLFU, so a flood of unique keys can't grow the memory used by the warm-up
+func (c *Cache) SetWarmup(duration time.Duration, targetHitRatio float64) {
+	c.warmupUntil = c.now().Add(duration)
+	c.warmupHitRatio = targetHitRatio
+	c.doorkeeper = newCountMinSketch(c.maxSize, 10*c.maxSize)
+}
+
+// admitted reports whether a new key may be stored, it ends the warm-up once it is over
+func (c *Cache) admitted(key CacheKey) bool {
+	if c.doorkeeper == nil {
+		return true
+	}
+	if !c.now().Before(c.warmupUntil) || c.warmupHitRatio > 0 && c.HitRatio(time.Minute) >= c.warmupHitRatio {
+		c.doorkeeper = nil
+		return true
+	}
+	if c.doorkeeper.estimate(key) >= 2 {
+		return true
+	}
+	atomic.AddInt64(&c.stats.warmupBypassed, 1)
+	return false
+}
+
+const (
+	sketchDepth      = 4
+	sketchMaxCounter = 15
+)
+
+// countMinSketch counts keys approximately in a fixed amount of memory, estimates are never
+// below the true count. Counters are 4 bits, packed 16 to a word, they saturate at 15 and are
+// all halved every resetEvery increments
+type countMinSketch struct {
+	rows       [sketchDepth][]uint64
+	mask       uint64
+	increments int
+	resetEvery int
+}
+
+func newCountMinSketch(width int, resetEvery int) *countMinSketch {
+	size := 16
+	for size < width {
+		size *= 2
+	}
+	sketch := &countMinSketch{}
+	for i := range sketch.rows {
+		sketch.rows[i] = make([]uint64, size/16)
+	}
+	sketch.mask = uint64(size - 1)
+	sketch.resetEvery = resetEvery
+	return sketch
+}
+
+// index returns the counter of key in row by double hashing, like the bloom filter
+func (s *countMinSketch) index(hash uint64, row int) uint64 {
+	return (hash + uint64(row)*(hash>>32|1)) & s.mask
+}
+
+func (s *countMinSketch) counter(row int, index uint64) uint64 {
+	return s.rows[row][index/16] >> (index % 16 * 4) & sketchMaxCounter
+}
+
+func (s *countMinSketch) increment(key CacheKey) {
+	hash := mixedHash(key)
+	for row := range s.rows {
+		index := s.index(hash, row)
+		if s.counter(row, index) < sketchMaxCounter {
+			s.rows[row][index/16] += 1 << (index % 16 * 4)
+		}
+	}
+	s.increments++
+	if s.resetEvery > 0 && s.increments >= s.resetEvery {
+		s.reset()
+	}
+}
+
+func (s *countMinSketch) estimate(key CacheKey) uint8 {
+	hash := mixedHash(key)
+	min := uint64(sketchMaxCounter)
+	for row := range s.rows {
+		if counter := s.counter(row, s.index(hash, row)); counter < min {
+			min = counter
+		}
+	}
+	return uint8(min)
+}
+
+// reset halves every counter
+func (s *countMinSketch) reset() {
+	for row := range s.rows {
+		for i := range s.rows[row] {
+			s.rows[row][i] = s.rows[row][i] >> 1 & 0x7777777777777777
+		}
+	}
+	s.increments /= 2
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWarmup(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(2, LRU)
+	cache.now = func() time.Time { return now }
+	cache.SetWarmup(time.Minute, 0)
+
+	test(t, cache, [][]interface{}{
+		{"Get", "1", nil},
+		{"Put", "1", "1"}, // requested once, bypassed
+		{"Get", "1", nil},
+		{"Put", "1", "1"}, // requested twice, admitted
+		{"Get", "1", "1"},
+	})
+	if cache.TryPut("2", "2") {
+		t.Errorf("key = 2 should be bypassed during warm-up")
+	}
+
+	now = now.Add(time.Minute)
+	test(t, cache, [][]interface{}{
+		{"Put", "3", "3"},
+		{"Get", "3", "3"},
+	})
+	if cache.Stats().WarmupBypassed != 2 {
+		t.Errorf("warm-up bypassed should be 2, but got %d", cache.Stats().WarmupBypassed)
+	}
+}
+
+func TestWarmupHitRatio(t *testing.T) {
+	cache := NewCache(2, LRU)
+	cache.SetWarmup(time.Hour, 0.5)
+	cache.Get("1")
+	cache.Get("1")
+	cache.Put("1", "1")
+	cache.Get("1") // hit ratio is 1/3
+
+	cache.Put("2", "2")
+	if _, _, err := cache.Peek("2"); err == nil {
+		t.Errorf("key = 2 should be bypassed while the hit ratio is low")
+	}
+	cache.Get("1") // hit ratio is 2/4
+	cache.Put("2", "2")
+	if _, _, err := cache.Peek("2"); err != nil {
+		t.Errorf("warm-up should end once the hit ratio reaches the target")
+	}
+}
+
+func TestWarmupDoorkeeperBounded(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(100, LRU)
+	cache.now = func() time.Time { return now }
+	cache.SetWarmup(time.Minute, 0)
+	words := len(cache.doorkeeper.rows[0])
+	for i := 0; i < 100000; i++ {
+		cache.Get(CacheKey(fmt.Sprint(i)))
+	}
+	if len(cache.doorkeeper.rows[0]) != words {
+		t.Errorf("doorkeeper should not grow with unique misses")
+	}
+
+	now = now.Add(time.Minute)
+	cache.Put("1", "1")
+	if cache.doorkeeper != nil {
+		t.Errorf("doorkeeper should be dropped once the warm-up ends")
+	}
+}
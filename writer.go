@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// SetWriterTracking records, for every write, the file and line of the first caller outside the
+// cache, so that a stale value can be traced back to the code that stored it. Writes made by the
+// cache itself, e.g. storing a parent hit, record the caller of the cache method. It costs a stack
+// walk per write, leave it off outside debugging sessions or use PutWithWriter instead
+func (c *Cache) SetWriterTracking(enabled bool) {
+	c.trackWriters = enabled
+}
+
+// PutWithWriter is Put recording writer, an ID chosen by the caller such as a component name, as
+// the last writer of key, it works with writer tracking off
+func (c *Cache) PutWithWriter(key CacheKey, value string, writer string) {
+	key = c.canonical(key)
+	c.put(key, value, "", 0)
+	if meta, ok := c.meta[key]; ok {
+		meta.writer = writer
+	}
+}
+
+// LastWriter returns the writer ID or file:line that last wrote key, it is empty when the write
+// happened while writer tracking was off
+func (c *Cache) LastWriter(key CacheKey) (string, error) {
+	key = c.canonical(key)
+	meta, ok := c.meta[key]
+	if !ok {
+		return "", errors.New("key not found")
+	}
+	return meta.writer, nil
+}
+
+// packageDir is the directory of the cache sources, frames from there are skipped by writer
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// writer returns the first caller outside the cache, however deep put was called from
+func writer() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if frame.File == "" {
+			return "unknown"
+		}
+		internal := filepath.Dir(frame.File) == packageDir && !strings.HasSuffix(frame.File, "_test.go")
+		if !internal {
+			return fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriterTracking(t *testing.T) {
+	cache := NewCache(2, LRU)
+	cache.Put("1", "1")
+	if writer, _ := cache.LastWriter("1"); writer != "" {
+		t.Errorf("writer = %q, but tracking is off", writer)
+	}
+
+	cache.SetWriterTracking(true)
+	cache.Put("2", "2")
+	cache.PutWithSource("1", "one", "loader")
+	for _, key := range []CacheKey{"1", "2"} {
+		writer, err := cache.LastWriter(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(writer, "writer_test.go:") {
+			t.Errorf("writer of %s = %q, but want writer_test.go:<line>", key, writer)
+		}
+	}
+	if _, err := cache.LastWriter("3"); err == nil {
+		t.Errorf("LastWriter should fail for a missing key")
+	}
+}
+
+func TestWriterTrackingEntryPoints(t *testing.T) {
+	parent := NewCache(2, LRU)
+	parent.Put("3", "3")
+	cache := NewCache(4, GDSF)
+	cache.AddParent(parent)
+	cache.SetWriterTracking(true)
+
+	cache.Get("3") // stored from the parent two calls below Get
+	cache.PutWithCost("1", "1", 2)
+	cache.TryPut("2", "2")
+	for _, key := range []CacheKey{"1", "2", "3"} {
+		if writer, _ := cache.LastWriter(key); !strings.HasPrefix(writer, "writer_test.go:") {
+			t.Errorf("writer of %s = %q, but want writer_test.go:<line>", key, writer)
+		}
+	}
+
+	cache.SetWriterTracking(false)
+	cache.PutWithWriter("1", "one", "loader")
+	if writer, _ := cache.LastWriter("1"); writer != "loader" {
+		t.Errorf("writer of 1 = %q, but want loader", writer)
+	}
+}